@@ -6,8 +6,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"video-encoding/shared/env"
+	"video-encoding/shared/jobwatch"
 	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
 
 	"syscall"
 	"time"
@@ -28,12 +29,14 @@ type s3Config struct {
 	presignGETTTL time.Duration
 }
 type application struct {
-	config config
-	store  store.Storage
-	logger *zap.SugaredLogger
+	config    config
+	store     store.Storage
+	logger    *zap.SugaredLogger
 	s3        *s3.Client
 	s3Presign *s3.PresignClient
-	producer *ProducerClient
+	watch     *jobwatch.Hub // nil when LISTEN/NOTIFY is unavailable
+	tenants   *tenant.Registry
+	openapi   []byte // encoded document served at /v1/openapi.json
 }
 
 type config struct {
@@ -42,9 +45,31 @@ type config struct {
 	env         string
 	apiURL      string
 	frontendURL string
-	producerGRPC string
+	corsOrigins []string // browser origins allowed to call the API
+
+	auth        authConfig
+	quotas      quotaConfig
+	idempotency idempotencyConfig
+	s3          s3Config
+	upload      uploadConfig
+	imports     importConfig
+	s3Events    s3EventsConfig
+	webhooks    webhookConfig
+	purge       purgeConfig
+}
+
+type purgeConfig struct {
+	enabled  bool          // run a purger in this process
+	delay    time.Duration // grace period for workers to stop before deleting
+	interval time.Duration
+}
 
-	s3 s3Config
+type webhookConfig struct {
+	dispatch     bool // run a dispatcher in this process
+	interval     time.Duration
+	batch        int
+	maxAttempts  int
+	allowPrivate bool // deliver to private addresses (local dev)
 }
 
 type dbConfig struct {
@@ -52,34 +77,90 @@ type dbConfig struct {
 	maxOpenConns int
 	maxIdleConns int
 	maxIdleTime  string
+	autoMigrate  bool // apply pending migrations on startup
 }
 
-func (app *application) mount() http.Handler {
+func (app *application) mount() *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(redactQueryTokens)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{env.GetString("CORS_ALLOWED_ORIGIN", "http://localhost:3000")},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins:   app.config.corsOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "X-API-Key", "X-Tenant-ID", "Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "ETag", "Retry-After", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
 
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Route("/v1", func(r chi.Router) {
+		r.Get("/openapi.json", app.ServeOpenAPI)
+
+		// object storage notifications (MinIO webhook, SNS subscription)
+		// carry their own token
+		r.Route("/ingest", func(r chi.Router) {
+			r.Use(middleware.Timeout(60 * time.Second))
 
-	
-r.Route("/v1", func(r chi.Router) {
+			r.Post("/s3-events", app.S3Events)
+		})
+
+		// everything else needs an authenticated caller
 		r.Route("/videos", func(r chi.Router) {
-			r.Get("/", app.ListVideos)
-			r.Post("/presign", app.PresignVideoUpload)
-			r.Get("/{id}", app.GetVideo)
-			r.Post("/{id}/jobs", app.CreateVideoJob)
-			r.Get("/{id}/playback", app.GetVideoPlayback)
+			r.Group(func(r chi.Router) {
+				r.Use(app.authenticate)
+				r.Use(middleware.Timeout(60 * time.Second))
+
+				r.Get("/", app.ListVideos)
+				r.With(app.idempotentWithin(app.config.s3.presignPUTTTL)).Post("/presign", app.PresignVideoUpload)
+				r.Post("/{id}/complete", app.CompleteUpload)
+				r.Post("/multipart", app.StartMultipartUpload)
+				r.Post("/import", app.ImportVideo)
+				r.Get("/{id}/import", app.GetVideoImport)
+				r.Get("/{id}/multipart", app.GetMultipartUpload)
+				r.Post("/{id}/multipart/parts", app.PresignUploadParts)
+				r.Post("/{id}/multipart/complete", app.CompleteMultipartUpload)
+				r.Delete("/{id}/multipart", app.AbortMultipartUpload)
+				r.Get("/{id}", app.GetVideo)
+				r.Patch("/{id}", app.UpdateVideo)
+				r.Delete("/{id}", app.DeleteVideo)
+				r.Get("/{id}/purge", app.GetVideoPurge)
+				r.Get("/{id}/jobs", app.ListVideoJobs)
+				r.With(app.idempotent).Post("/{id}/jobs", app.CreateVideoJob)
+				r.Get("/{id}/playback", app.GetVideoPlayback)
+			})
+
+			// long-lived stream (SSE / WebSocket), no request timeout; the
+			// only route taking ?access_token=, as EventSource can't set headers
+			r.With(app.authenticateStream).Get("/{id}/events", app.VideoEvents)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(app.authenticate)
+
+			r.With(middleware.Timeout(60*time.Second)).Get("/usage", app.GetUsage)
+
+			r.Route("/jobs", func(r chi.Router) {
+				r.Use(middleware.Timeout(60 * time.Second))
+
+				r.Get("/{jobId}", app.GetJob)
+			})
+
+			// webhooks see events of every video of every tenant
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Use(middleware.Timeout(60 * time.Second))
+				r.Use(requireOperator)
+
+				r.Post("/", app.CreateWebhook)
+				r.Get("/", app.ListWebhooks)
+				r.Get("/{id}", app.GetWebhook)
+				r.Patch("/{id}", app.UpdateWebhook)
+				r.Delete("/{id}", app.DeleteWebhook)
+				r.Get("/{id}/deliveries", app.ListWebhookDeliveries)
+			})
 		})
 	})
 	return r
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"video-encoding/shared/auth"
+	"video-encoding/shared/env"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+)
+
+type authConfig struct {
+	enabled bool
+	authn   *auth.Authenticator
+}
+
+// loadAuthConfig reads the JWT and API key settings. With auth enabled at
+// least one kind of credential must be configured.
+func loadAuthConfig() (authConfig, error) {
+	cfg := authConfig{enabled: env.GetBool("AUTH_ENABLED", true)}
+	if !cfg.enabled {
+		return cfg, nil
+	}
+
+	keys, err := auth.ParseAPIKeys(env.GetString("API_KEYS", ""))
+	if err != nil {
+		return cfg, err
+	}
+	authn := &auth.Authenticator{Keys: keys}
+
+	v := &auth.JWTVerifier{
+		Secret:   []byte(env.GetString("JWT_HS256_SECRET", "")),
+		Issuer:   env.GetString("JWT_ISSUER", ""),
+		Audience: env.GetString("JWT_AUDIENCE", ""),
+		Leeway:   env.GetDuration("JWT_LEEWAY", 30*time.Second),
+	}
+	if path := env.GetString("JWT_JWKS_FILE", ""); path != "" {
+		if v.Keys, err = auth.LoadJWKS(path); err != nil {
+			return cfg, err
+		}
+	}
+	if len(v.Secret) > 0 || len(v.Keys) > 0 {
+		authn.JWT = v
+	}
+
+	if authn.JWT == nil && len(keys) == 0 {
+		return cfg, errors.New("AUTH_ENABLED needs JWT_HS256_SECRET, JWT_JWKS_FILE or API_KEYS")
+	}
+	cfg.authn = authn
+	return cfg, nil
+}
+
+// authenticate puts the caller and its tenant in the request context, or
+// answers 401. With auth disabled every request is an admin, of the tenant
+// in X-Tenant-ID if set.
+func (app *application) authenticate(next http.Handler) http.Handler {
+	return app.authenticateWith(false, next)
+}
+
+// authenticateStream is authenticate that also takes ?access_token=, for
+// the event streams EventSource opens.
+func (app *application) authenticateStream(next http.Handler) http.Handler {
+	return app.authenticateWith(true, next)
+}
+
+func (app *application) authenticateWith(query bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := auth.Anonymous
+		if app.config.auth.enabled {
+			var err error
+			if query {
+				p, err = app.config.auth.authn.AuthenticateQuery(r)
+			} else {
+				p, err = app.config.auth.authn.Authenticate(r)
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				httpx.Fail(w, 401, "UNAUTHORIZED", err.Error())
+				return
+			}
+		} else {
+			p.Tenant = r.Header.Get("X-Tenant-ID")
+		}
+
+		t, err := app.tenants.Get(p.Tenant)
+		if err != nil {
+			httpx.Fail(w, 403, "UNKNOWN_TENANT", err.Error())
+			return
+		}
+		p.Tenant = t.ID
+
+		ctx := auth.NewContext(r.Context(), p)
+		ctx = tenant.NewContext(ctx, t.ID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// redactQueryTokens hides credentials sent in the query string
+// (?access_token=, the S3 events ?token=) from the request log, which
+// prints RequestURI. Handlers still read them from r.URL.
+func redactQueryTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		redacted := false
+		for _, k := range []string{"access_token", "token"} {
+			if q.Has(k) {
+				q.Set(k, "REDACTED")
+				redacted = true
+			}
+		}
+		if redacted {
+			r = r.WithContext(r.Context())
+			r.RequestURI = r.URL.EscapedPath() + "?" + q.Encode()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireOperator answers 403 to callers that aren't admins of the default
+// tenant, for routes that span tenants.
+func requireOperator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := auth.FromContext(r.Context())
+		if !p.Admin || p.Tenant != tenant.Default {
+			httpx.Fail(w, 403, "FORBIDDEN", "admins of the default tenant only")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tenantOf returns where the objects of ctx's tenant live. A tenant
+// removed from TENANTS since has no bucket, so its S3 calls fail rather
+// than reach another tenant's objects.
+func (app *application) tenantOf(ctx context.Context) tenant.Tenant {
+	id, _ := tenant.FromContext(ctx)
+	t, _ := app.tenants.Get(id)
+	return t
+}
+
+// ownedVideo returns the video if the caller may access it. Videos of
+// other owners are reported as not found, so their ids don't leak.
+func (app *application) ownedVideo(ctx context.Context, id string) (store.Video, error) {
+	v, err := app.store.Video.Get(ctx, id)
+	if err != nil {
+		return store.Video{}, err
+	}
+	if !auth.FromContext(ctx).CanAccess(v.Owner) {
+		return store.Video{}, store.ErrNotFound
+	}
+	return v, nil
+}
+
+// ownerOf is the owner recorded on videos created by the caller.
+func ownerOf(ctx context.Context) *string {
+	p := auth.FromContext(ctx)
+	if p.Subject == "" {
+		return nil
+	}
+	return &p.Subject
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"video-encoding/shared/auth"
+	"video-encoding/shared/events"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+
+	"github.com/go-chi/chi"
+)
+
+// DeleteVideo cancels the video's running job, deletes its rows and
+// schedules the removal of its S3 objects. The purge runs in the background;
+// poll GET /v1/videos/{id}/purge for its progress.
+func (app *application) DeleteVideo(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	v, err := app.ownedVideo(r.Context(), videoID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	var msgs []store.OutboxEntry
+	if v.LatestJobID != nil {
+		j, err := app.store.Job.Get(r.Context(), *v.LatestJobID)
+		if err == nil && (j.Status == store.JobQueued || j.Status == store.JobProcessing) {
+			entry, err := events.New(events.JobCancelled, events.SourceAPI, v.ID, j.ID, events.JobState{
+				Status:     string(store.JobCancelled),
+				Progress:   j.Progress,
+				Renditions: j.AvailableRenditions,
+				Error:      "video deleted",
+			}).Outbox()
+			if err == nil {
+				msgs = append(msgs, entry)
+			}
+		}
+	}
+
+	p, err := app.store.Video.Delete(r.Context(), v.ID, app.videoPrefixes(v), app.config.purge.delay, msgs...)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	// parts of an unfinished multipart upload are not objects the purger
+	// would find under the prefixes
+	if v.UploadState == store.UploadInProgress && v.UploadID != nil {
+		if err := app.abortMultipart(context.WithoutCancel(r.Context()), v.InputKey, *v.UploadID); err != nil {
+			app.logger.Warnw("abort multipart upload failed", "videoId", v.ID, "err", err)
+		}
+	}
+
+	httpx.Accepted(w, "video deleted, purge scheduled", purgeResp(p))
+}
+
+func (app *application) GetVideoPurge(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	p, err := app.store.Purge.Get(r.Context(), videoID)
+	if err == nil && !auth.FromContext(r.Context()).CanAccess(p.Owner) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "no purge for this video")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	httpx.Ok(w, "purge fetched", purgeResp(p))
+}
+
+// videoPrefixes lists every S3 prefix holding objects of v.
+func (app *application) videoPrefixes(v store.Video) []string {
+	t, _ := app.tenants.Get(v.TenantID)
+	base := t.BasePath
+	candidates := []string{
+		base + "inputs/" + v.ID + "-",
+		base + "thumbnails/" + v.ID + "-",
+		base + "outputs/" + v.ID + "/",
+		v.InputKey,
+		v.ThumbnailKey,
+	}
+
+	out := make([]string, 0, len(candidates))
+	seen := map[string]bool{}
+	for _, p := range candidates {
+		if p == "" || seen[p] {
+			continue
+		}
+		// exact keys already covered by a prefix add nothing
+		covered := false
+		for _, q := range out {
+			if strings.HasPrefix(p, q) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, p)
+		}
+		seen[p] = true
+	}
+	return out
+}
+
+func purgeResp(p store.Purge) types.PurgeResp {
+	out := types.PurgeResp{
+		VideoID:        p.VideoID,
+		Status:         string(p.Status),
+		Prefixes:       p.Prefixes,
+		ObjectsDeleted: p.ObjectsDeleted,
+		Attempts:       p.Attempts,
+		LastError:      p.LastError,
+		CreatedAt:      p.CreatedAt,
+		CompletedAt:    p.CompletedAt,
+	}
+	if p.Status == store.PurgePending {
+		next := p.NextAttemptAt
+		out.NextAttemptAt = &next
+	}
+	return out
+}
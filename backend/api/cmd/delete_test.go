@@ -0,0 +1,56 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+)
+
+func TestVideoPrefixes(t *testing.T) {
+	tenants, err := tenant.Parse("acme=:acme/", tenant.Tenant{Bucket: "videos", BasePath: "reels/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &application{tenants: tenants}
+
+	tests := []struct {
+		name string
+		v    store.Video
+		want []string
+	}{
+		{
+			"keys under the video's prefixes",
+			store.Video{ID: "v1", InputKey: "reels/inputs/v1-clip.mp4", ThumbnailKey: "reels/thumbnails/v1-a1b2c3d4-thumb.jpg"},
+			[]string{"reels/inputs/v1-", "reels/thumbnails/v1-", "reels/outputs/v1/"},
+		},
+		{
+			"tenant base path",
+			store.Video{ID: "v2", TenantID: "acme", InputKey: "acme/inputs/v2-clip.mp4"},
+			[]string{"acme/inputs/v2-", "acme/thumbnails/v2-", "acme/outputs/v2/"},
+		},
+		{
+			// created before the key layout, or under another base path
+			"keys outside the prefixes",
+			store.Video{ID: "v3", InputKey: "legacy/v3.mp4", ThumbnailKey: "legacy/v3.jpg"},
+			[]string{"reels/inputs/v3-", "reels/thumbnails/v3-", "reels/outputs/v3/", "legacy/v3.mp4", "legacy/v3.jpg"},
+		},
+		{
+			"same key twice",
+			store.Video{ID: "v4", InputKey: "legacy/v4", ThumbnailKey: "legacy/v4"},
+			[]string{"reels/inputs/v4-", "reels/thumbnails/v4-", "reels/outputs/v4/", "legacy/v4"},
+		},
+		{
+			// v5's prefixes must not cover v50's objects, and the other way round
+			"id prefix of another id",
+			store.Video{ID: "v5", InputKey: "reels/inputs/v50-clip.mp4"},
+			[]string{"reels/inputs/v5-", "reels/thumbnails/v5-", "reels/outputs/v5/", "reels/inputs/v50-clip.mp4"},
+		},
+	}
+	for _, tt := range tests {
+		if got := app.videoPrefixes(tt.v); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: videoPrefixes = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
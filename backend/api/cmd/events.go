@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"video-encoding/shared/jobwatch"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+
+	"github.com/go-chi/chi"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	eventsResyncEvery    = 15 * time.Second // re-read even without a notification
+	eventsPollEvery      = 2 * time.Second  // used when LISTEN/NOTIFY is unavailable
+	eventsHeartbeatEvery = 20 * time.Second
+)
+
+// VideoEvents streams PlaybackResp updates for a video as Server-Sent Events,
+// or over a WebSocket when the client asks for an upgrade. The stream ends
+// after the job reaches a terminal state.
+func (app *application) VideoEvents(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	if _, err := app.ownedVideo(r.Context(), videoID); err != nil {
+		if errors.Is(err, store.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		app.videoEventsWS(w, r, videoID)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// the server WriteTimeout would cut the stream after 30s
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	seq := 0
+	send := func(p types.PlaybackResp) error {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		seq++
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: playback\ndata: %s\n\n", seq, b); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	ping := func() error {
+		if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := app.watchPlayback(r.Context(), videoID, send, ping); err != nil && r.Context().Err() == nil {
+		app.logger.Warnw("video events stream ended", "videoId", videoID, "err", err)
+	}
+}
+
+// checkOrigin accepts requests without an Origin (not from a browser),
+// from the API's own host and from CORS_ALLOWED_ORIGIN.
+func (app *application) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+	for _, o := range app.config.corsOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q not allowed", origin)
+}
+
+// originList splits the comma-separated CORS_ALLOWED_ORIGIN.
+func originList(s string) []string {
+	var out []string
+	for _, o := range strings.Split(s, ",") {
+		if o = strings.TrimSuffix(strings.TrimSpace(o), "/"); o != "" {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// wsPing sends an empty ping frame; the client's pong is read and dropped
+// by the receive loop.
+var wsPing = websocket.Codec{
+	Marshal: func(any) ([]byte, byte, error) { return nil, websocket.PingFrame, nil },
+}
+
+func (app *application) videoEventsWS(w http.ResponseWriter, r *http.Request, videoID string) {
+	srv := websocket.Server{
+		// CORS doesn't cover upgrades: any page could open a socket with
+		// the caller's cookies or query token
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			return app.checkOrigin(r)
+		},
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			// the hijacked conn keeps the server's ReadTimeout and
+			// WriteTimeout, which would close the socket within seconds
+			if err := ws.SetDeadline(time.Time{}); err != nil {
+				return
+			}
+
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			// the client never sends anything; a read error means it left
+			go func() {
+				var discard []byte
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+				cancel()
+			}()
+
+			send := func(p types.PlaybackResp) error {
+				return websocket.JSON.Send(ws, p)
+			}
+			// keeps proxies from closing an idle socket and finds dead peers
+			ping := func() error {
+				return wsPing.Send(ws, nil)
+			}
+
+			if err := app.watchPlayback(ctx, videoID, send, ping); err != nil && ctx.Err() == nil {
+				app.logger.Warnw("video events socket ended", "videoId", videoID, "err", err)
+			}
+		},
+	}
+	srv.ServeHTTP(w, r)
+}
+
+// watchPlayback sends the current playback state and then every change to
+// it until the latest job is terminal or ctx is done. Changes are picked up
+// from the job_updates notifications, so every API replica sees them.
+func (app *application) watchPlayback(ctx context.Context, videoID string, send func(types.PlaybackResp) error, ping func() error) error {
+	var updates <-chan jobwatch.Update // nil without a hub: never fires
+	resync := eventsResyncEvery
+
+	if app.watch != nil {
+		ch, unsubscribe := app.watch.SubscribeVideo(videoID)
+		defer unsubscribe()
+		updates = ch
+	} else {
+		resync = eventsPollEvery
+	}
+
+	tick := time.NewTicker(resync)
+	defer tick.Stop()
+	heartbeat := time.NewTicker(eventsHeartbeatEvery)
+	defer heartbeat.Stop()
+
+	var last *types.PlaybackResp
+	for {
+		v, err := app.store.Video.Get(ctx, videoID)
+		if err != nil {
+			return err
+		}
+		p, err := app.playbackFor(ctx, v)
+		if err != nil {
+			return err
+		}
+
+		if last == nil || playbackChanged(*last, p) {
+			if err := send(p); err != nil {
+				return err
+			}
+			last = &p
+		}
+		if playbackTerminal(p) {
+			return nil
+		}
+
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-updates:
+				waiting = false
+			case <-tick.C:
+				waiting = false
+			case <-heartbeat.C:
+				if ping != nil {
+					if err := ping(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+func playbackTerminal(p types.PlaybackResp) bool {
+	if p.JobID == nil {
+		return false // no job yet, wait for one
+	}
+	switch store.JobStatus(p.Status) {
+	case store.JobCompleted, store.JobFailed, store.JobCancelled:
+		return true
+	}
+	return false
+}
+
+// playbackChanged ignores MasterURL: it is re-signed on every read.
+func playbackChanged(a, b types.PlaybackResp) bool {
+	return a.Status != b.Status ||
+		a.Progress != b.Progress ||
+		a.PlaybackReady != b.PlaybackReady ||
+		strPtr(a.JobID) != strPtr(b.JobID) ||
+		strPtr(a.MasterKey) != strPtr(b.MasterKey) ||
+		!slices.Equal(a.AvailableRenditions, b.AvailableRenditions) ||
+		!slices.EqualFunc(a.Renditions, b.Renditions, func(x, y types.RenditionResp) bool {
+			return x.Name == y.Name && x.Status == y.Status
+		})
+}
+
+func strPtr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
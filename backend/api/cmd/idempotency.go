@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"video-encoding/shared/auth"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+)
+
+const (
+	idempotencyMaxKey  = 255
+	idempotencyMaxBody = 1 << 20
+)
+
+type idempotencyConfig struct {
+	ttl  time.Duration // how long responses are replayed
+	lock time.Duration // how long a request may hold its key; above the request timeout
+}
+
+// replayedHeaders are stored with the response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// idempotent replays the stored response to requests that repeat the
+// Idempotency-Key of an earlier one. The key is scoped to the tenant and
+// caller. Reusing it with another method, path or body answers 422; while
+// the first request still runs, repeats answer 409.
+//
+// Responses a retry could change (5xx, 409, 429) are not stored: the key
+// is released and the next retry runs the request again.
+func (app *application) idempotent(next http.Handler) http.Handler {
+	return app.idempotentWithin(app.config.idempotency.ttl)(next)
+}
+
+// idempotentWithin is idempotent, replaying responses for at most ttl
+// (and never longer than IDEMPOTENCY_TTL). Responses with presigned URLs
+// use the URLs' lifetime: replayed any later, they'd be dead.
+func (app *application) idempotentWithin(ttl time.Duration) func(http.Handler) http.Handler {
+	ttl = min(ttl, app.config.idempotency.ttl)
+	return func(next http.Handler) http.Handler {
+		return app.idempotentHandler(next, ttl)
+	}
+}
+
+func (app *application) idempotentHandler(next http.Handler, ttl time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > idempotencyMaxKey {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", "Idempotency-Key is longer than 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, idempotencyMaxBody+1))
+		if err != nil {
+			httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+			return
+		}
+		if len(body) > idempotencyMaxBody {
+			httpx.Fail(w, 413, "VALIDATION_ERROR", "request body too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		tenantID, _ := tenant.FromContext(ctx)
+		rec := store.IdempotencyRecord{
+			TenantID:    tenantID,
+			Subject:     auth.FromContext(ctx).Subject,
+			Key:         key,
+			Fingerprint: requestFingerprint(r, body),
+		}
+
+		prev, err := app.store.Idempotency.Begin(ctx, rec, app.config.idempotency.lock, ttl)
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			w.Header().Set("Retry-After", "1")
+			httpx.Fail(w, 409, "IDEMPOTENCY_IN_PROGRESS", "a request with this Idempotency-Key is in progress")
+			return
+		case err != nil:
+			httpx.Fail(w, 500, "DB_ERROR", err.Error())
+			return
+		case prev != nil && prev.Fingerprint != rec.Fingerprint:
+			httpx.Fail(w, 422, "IDEMPOTENCY_KEY_REUSED", "this Idempotency-Key was used for a different request")
+			return
+		case prev != nil && !prev.Completed:
+			w.Header().Set("Retry-After", "1")
+			httpx.Fail(w, 409, "IDEMPOTENCY_IN_PROGRESS", "a request with this Idempotency-Key is in progress")
+			return
+		case prev != nil:
+			for k, v := range prev.ResponseHeaders {
+				w.Header().Set(k, v)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(prev.ResponseStatus)
+			_, _ = w.Write(prev.ResponseBody)
+			return
+		}
+
+		// the key is ours: release it unless a final response is stored,
+		// also when the handler panics
+		stored := false
+		defer func() {
+			if !stored {
+				if err := app.store.Idempotency.Release(context.WithoutCancel(ctx), rec); err != nil {
+					app.logger.Warnw("idempotency key not released", "key", key, "err", err)
+				}
+			}
+		}()
+
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		if rw.status >= 500 || rw.status == http.StatusConflict || rw.status == http.StatusTooManyRequests {
+			return
+		}
+		rec.ResponseStatus = rw.status
+		rec.ResponseBody = rw.body.Bytes()
+		rec.ResponseHeaders = map[string]string{}
+		for _, h := range replayedHeaders {
+			if v := rw.Header().Get(h); v != "" {
+				rec.ResponseHeaders[h] = v
+			}
+		}
+		if err := app.store.Idempotency.Complete(context.WithoutCancel(ctx), rec); err != nil {
+			app.logger.Warnw("idempotent response not stored", "key", key, "err", err)
+			return
+		}
+		stored = true
+	})
+}
+
+// requestFingerprint hashes what must match for a key to be replayed.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter passes a response through and keeps a copy.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// sweepIdempotencyKeys deletes expired keys now and then; expired keys are
+// already ignored, this only keeps the table small.
+func (app *application) sweepIdempotencyKeys(ctx context.Context) {
+	t := time.NewTicker(10 * time.Minute)
+	defer t.Stop()
+	for {
+		for {
+			n, err := app.store.Idempotency.DeleteExpired(ctx, 1000)
+			if err != nil && ctx.Err() == nil {
+				app.logger.Warnw("idempotency sweep failed", "err", err)
+			}
+			if err != nil || n < 1000 {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	fp := func(method, target, body string) string {
+		return requestFingerprint(httptest.NewRequest(method, target, nil), []byte(body))
+	}
+	base := fp("POST", "/v1/videos/presign", `{"title":"a"}`)
+
+	tests := []struct {
+		name string
+		fp   string
+		same bool
+	}{
+		{"same request", fp("POST", "/v1/videos/presign", `{"title":"a"}`), true},
+		// the query string is not part of what a key stands for
+		{"query string", fp("POST", "/v1/videos/presign?x=1", `{"title":"a"}`), true},
+		{"other body", fp("POST", "/v1/videos/presign", `{"title":"b"}`), false},
+		{"empty body", fp("POST", "/v1/videos/presign", ""), false},
+		{"other path", fp("POST", "/v1/videos/123/jobs", `{"title":"a"}`), false},
+		{"other method", fp("PUT", "/v1/videos/presign", `{"title":"a"}`), false},
+	}
+	for _, tt := range tests {
+		if got := tt.fp == base; got != tt.same {
+			t.Errorf("%s: same fingerprint = %v, want %v", tt.name, got, tt.same)
+		}
+	}
+}
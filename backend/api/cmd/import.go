@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+	"video-encoding/shared/utils"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// ImportVideo creates a pending_upload video from a source URL. The ingest
+// worker fetches it in the background; follow it with GET /{id}/import.
+func (app *application) ImportVideo(w http.ResponseWriter, r *http.Request) {
+	var req types.ImportVideoReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	req.SourceURL = strings.TrimSpace(req.SourceURL)
+	req.SHA256 = strings.ToLower(strings.TrimSpace(req.SHA256))
+
+	src, err := url.Parse(req.SourceURL)
+	if err != nil || src.Host == "" || (src.Scheme != "http" && src.Scheme != "https") {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "sourceUrl must be an absolute http(s) URL")
+		return
+	}
+	if req.SHA256 != "" {
+		if b, err := hex.DecodeString(req.SHA256); err != nil || len(b) != 32 {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", "sha256 must be a hex SHA-256 digest")
+			return
+		}
+	}
+	if req.Pipeline == "" {
+		req.Pipeline = types.PipelineHLS
+	}
+	if err := types.ValidatePipeline(req.Pipeline); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	if err := req.Options.Validate(); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	videoID := uuid.NewString()
+	if !app.reserveUpload(w, r, videoID) {
+		return
+	}
+
+	filename := strings.TrimSpace(req.Filename)
+	if filename == "" {
+		filename = path.Base(src.Path)
+	}
+	if filename == "" || filename == "/" || filename == "." {
+		filename = "source"
+	}
+
+	t := app.tenantOf(r.Context())
+	v := store.Video{
+		ID:          videoID,
+		TenantID:    t.ID,
+		Title:       req.Title,
+		Description: req.Description,
+		Filename:    filename,
+		InputKey:    t.BasePath + "inputs/" + videoID + "-" + utils.SafeFilename(filename),
+		Status:      store.PendingUpload,
+		UploadState: store.UploadImporting,
+		Owner:       ownerOf(r.Context()),
+	}
+	imp := store.Import{
+		VideoID:   videoID,
+		SourceURL: src.String(),
+		Enqueue:   req.Enqueue == nil || *req.Enqueue,
+		Pipeline:  req.Pipeline,
+		Options:   req.Options,
+	}
+	if req.SHA256 != "" {
+		imp.ChecksumSHA256 = &req.SHA256
+	}
+
+	if err := app.store.Import.Create(r.Context(), v, imp); err != nil {
+		app.logger.Errorw("import create failed", "err", err)
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	v, err = app.store.Video.Get(r.Context(), videoID)
+	if err == nil {
+		imp, err = app.store.Import.Get(r.Context(), videoID)
+	}
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	httpx.Accepted(w, "import scheduled", types.ImportVideoResp{
+		Video:  app.videoResp(r, v),
+		Import: importResp(imp),
+	})
+}
+
+func (app *application) GetVideoImport(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	_, err := app.ownedVideo(r.Context(), videoID)
+	var imp store.Import
+	if err == nil {
+		imp, err = app.store.Import.Get(r.Context(), videoID)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "no import for this video")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	httpx.Ok(w, "import", importResp(imp))
+}
+
+func importResp(imp store.Import) types.ImportResp {
+	return types.ImportResp{
+		VideoID:       imp.VideoID,
+		SourceURL:     imp.SourceURL,
+		Status:        string(imp.Status),
+		BytesReceived: imp.BytesReceived,
+		BytesTotal:    imp.BytesTotal,
+		Attempts:      imp.Attempts,
+		LastError:     imp.LastError,
+		CreatedAt:     imp.CreatedAt,
+		UpdatedAt:     imp.UpdatedAt,
+		CompletedAt:   imp.CompletedAt,
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"video-encoding/shared/netguard"
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+	"video-encoding/shared/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.uber.org/zap"
+)
+
+const (
+	ingestPartSize    = 16 << 20 // also the progress granularity
+	ingestMaxAttempts = 5
+)
+
+type importConfig struct {
+	enabled      bool // run an ingester in this process
+	interval     time.Duration
+	timeout      time.Duration // per attempt
+	allowPrivate bool          // allow sources on private networks (local dev)
+}
+
+// errPermanent marks ingest failures that a retry won't fix.
+var errPermanent = errors.New("permanent")
+
+func permanent(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errPermanent, fmt.Sprintf(format, args...))
+}
+
+// ingester streams imported videos from their source URL into the inputs
+// prefix. Imports are leased in the DB, so every API replica can run one.
+type ingester struct {
+	store    store.Storage
+	s3       *s3.Client
+	tenants  *tenant.Registry
+	log      *zap.SugaredLogger
+	client   *http.Client
+	interval time.Duration
+	timeout  time.Duration
+	maxBytes int64
+
+	// newJob builds the video's job, enqueued once the input is in place
+	newJob func(v store.Video, req types.CreateVideoJobReq) (store.Job, []store.OutboxEntry, error)
+}
+
+func newIngester(cfg importConfig, maxBytes int64, st store.Storage, s3Client *s3.Client, tenants *tenant.Registry, log *zap.SugaredLogger) *ingester {
+	// imports must not reach internal services
+	dialer := netguard.Dialer(10*time.Second, cfg.allowPrivate)
+	return &ingester{
+		store:    st,
+		s3:       s3Client,
+		tenants:  tenants,
+		log:      log,
+		interval: cfg.interval,
+		timeout:  cfg.timeout,
+		maxBytes: maxBytes,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: 30 * time.Second,
+			},
+		},
+	}
+}
+
+func (in *ingester) Run(ctx context.Context) {
+	for {
+		// one at a time: an import holds a part buffer and a long download
+		batch, err := in.store.Import.Claim(ctx, 1, in.timeout+time.Minute)
+		if err != nil && ctx.Err() == nil {
+			in.log.Warnw("import claim failed", "err", err)
+		}
+
+		for _, imp := range batch {
+			in.ingest(ctx, imp)
+		}
+
+		if len(batch) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(in.interval):
+		}
+	}
+}
+
+func (in *ingester) ingest(ctx context.Context, imp store.Import) {
+	log := in.log.With("videoId", imp.VideoID, "attempt", imp.Attempts)
+
+	ctx, cancel := context.WithTimeout(ctx, in.timeout)
+	defer cancel()
+
+	err := in.run(ctx, imp)
+	if errors.Is(err, store.ErrNotFound) {
+		log.Infow("video deleted during import")
+		return
+	}
+	if err != nil {
+		log.Warnw("import failed", "err", err)
+
+		var retryAt *time.Time
+		if !errors.Is(err, errPermanent) && imp.Attempts < ingestMaxAttempts {
+			t := time.Now().Add(purgeBackoff(imp.Attempts))
+			retryAt = &t
+		}
+		msg := strings.TrimPrefix(err.Error(), errPermanent.Error()+": ")
+		if err := in.store.Import.Fail(context.WithoutCancel(ctx), imp.VideoID, "import: "+msg, retryAt); err != nil {
+			log.Errorw("import status not saved", "err", err)
+		}
+		return
+	}
+
+	if err := in.store.Import.Complete(ctx, imp.VideoID); err != nil {
+		log.Errorw("import status not saved", "err", err)
+		return
+	}
+	log.Infow("import completed")
+}
+
+// run fetches the source into the video's input key, then completes the
+// upload and enqueues the job in one transaction.
+func (in *ingester) run(ctx context.Context, imp store.Import) error {
+	v, err := in.store.Video.Get(ctx, imp.VideoID)
+	if err != nil {
+		return err
+	}
+
+	if v.Status != store.PendingUpload {
+		return nil // a previous attempt got through
+	}
+
+	t, err := in.tenants.Get(v.TenantID)
+	if err != nil {
+		return permanent("%v", err)
+	}
+	size, contentType, err := in.fetch(ctx, imp, t.Bucket, v.InputKey)
+	if err != nil {
+		return err
+	}
+
+	var job *store.Job
+	var msgs []store.OutboxEntry
+	if imp.Enqueue {
+		j, m, err := in.newJob(v, types.CreateVideoJobReq{Pipeline: imp.Pipeline, Options: imp.Options})
+		if err != nil {
+			return permanent("%v", err)
+		}
+		job, msgs = &j, m
+	}
+
+	_, err = completeUpload(ctx, in.store, v.ID, size, contentType, job, msgs...)
+	if errors.Is(err, store.ErrConflict) {
+		return nil
+	}
+	return err
+}
+
+// fetch streams the source into key in bucket with a multipart upload, validating
+// the content type, the leading bytes, the size limit and the checksum on
+// the way. It returns the size and the sniffed content type.
+func (in *ingester) fetch(ctx context.Context, imp store.Import, bucket, key string) (int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imp.SourceURL, nil)
+	if err != nil {
+		return 0, "", permanent("bad source url: %v", err)
+	}
+	resp, err := in.client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && errors.Is(opErr.Err, netguard.ErrPrivateAddr) {
+			return 0, "", permanent("%v", opErr.Err)
+		}
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusRequestTimeout:
+		return 0, "", fmt.Errorf("source answered %s", resp.Status)
+	default:
+		return 0, "", permanent("source answered %s", resp.Status)
+	}
+
+	var total *int64
+	if resp.ContentLength >= 0 {
+		if resp.ContentLength > in.maxBytes {
+			return 0, "", permanent("source is %d bytes, the limit is %d", resp.ContentLength, in.maxBytes)
+		}
+		total = &resp.ContentLength
+	}
+	ct, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	ct = strings.TrimSpace(strings.ToLower(ct))
+	if ct != "" && !strings.HasPrefix(ct, "video/") && ct != "application/octet-stream" && ct != "binary/octet-stream" {
+		return 0, "", permanent("source content type %q is not a video", ct)
+	}
+
+	head := make([]byte, sniffBytes)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return 0, "", err
+	}
+	head = head[:n]
+	contentType := sniffVideo(head)
+	if contentType == "" {
+		return 0, "", permanent("unrecognised container format")
+	}
+
+	_ = in.store.Import.Progress(ctx, imp.VideoID, 0, total)
+
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head), resp.Body), hash)
+
+	mu, err := in.s3.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	completed := false
+	defer func() {
+		if !completed {
+			_, _ = in.s3.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(bucket),
+				Key:      aws.String(key),
+				UploadId: mu.UploadId,
+			})
+		}
+	}()
+
+	var parts []s3types.CompletedPart
+	var size int64
+	buf := make([]byte, ingestPartSize)
+	for {
+		n, err := io.ReadFull(body, buf)
+		if n > 0 {
+			size += int64(n)
+			if size > in.maxBytes {
+				return 0, "", permanent("source exceeds the limit of %d bytes", in.maxBytes)
+			}
+
+			num := int32(len(parts) + 1)
+			out, perr := in.s3.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:     aws.String(bucket),
+				Key:        aws.String(key),
+				UploadId:   mu.UploadId,
+				PartNumber: aws.Int32(num),
+				Body:       bytes.NewReader(buf[:n]),
+			})
+			if perr != nil {
+				return 0, "", perr
+			}
+			parts = append(parts, s3types.CompletedPart{PartNumber: aws.Int32(num), ETag: out.ETag})
+
+			if err := in.store.Import.Progress(ctx, imp.VideoID, size, total); err != nil {
+				return 0, "", err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	if total != nil && size != *total {
+		return 0, "", fmt.Errorf("source sent %d of %d bytes", size, *total)
+	}
+	if imp.ChecksumSHA256 != nil {
+		if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, *imp.ChecksumSHA256) {
+			return 0, "", permanent("sha256 mismatch: got %s", sum)
+		}
+	}
+
+	_, err = in.s3.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        mu.UploadId,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	completed = true
+	return size, contentType, nil
+}
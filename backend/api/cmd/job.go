@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+	"video-encoding/shared/utils"
+
+	"github.com/go-chi/chi"
+)
+
+// ListVideoJobs returns every job of a video, newest first, so earlier
+// attempts stay visible after a new job replaces latestJobId.
+func (app *application) ListVideoJobs(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	if _, err := app.ownedVideo(r.Context(), videoID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	limit := utils.ParseInt(r.URL.Query().Get("limit"), 20)
+	offset := utils.ParseInt(r.URL.Query().Get("offset"), 0)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	jobs, total, err := app.store.Job.ListByVideo(r.Context(), videoID, limit, offset)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	items := make([]types.JobResp, 0, len(jobs))
+	for _, j := range jobs {
+		items = append(items, jobResp(j))
+	}
+
+	httpx.Ok(w, "jobs listed", types.JobListResp{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GetJob returns a job with its event timeline.
+func (app *application) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobId")
+	if strings.TrimSpace(jobID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "jobId is required")
+		return
+	}
+
+	j, err := app.store.Job.Get(r.Context(), jobID)
+	if err == nil {
+		// jobs belong to the owner of their video
+		_, err = app.ownedVideo(r.Context(), j.VideoID)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "job not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	evs, err := app.store.Job.Events(r.Context(), jobID)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	rends, err := app.store.Rendition.ListByJob(r.Context(), jobID)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	out := types.JobDetailResp{
+		JobResp:    jobResp(j),
+		Renditions: renditionResps(rends),
+		Events:     make([]types.JobEventResp, 0, len(evs)),
+	}
+	for _, e := range evs {
+		if e.Attempt > out.Attempts {
+			out.Attempts = e.Attempt
+		}
+		out.Events = append(out.Events, types.JobEventResp{
+			ID:        e.ID,
+			Type:      e.Type,
+			Status:    string(e.Status),
+			Progress:  e.Progress,
+			Attempt:   e.Attempt,
+			Worker:    e.Worker,
+			Error:     e.Error,
+			Details:   e.Details,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	httpx.Ok(w, "job fetched", out)
+}
+
+func jobResp(j store.Job) types.JobResp {
+	rends := j.AvailableRenditions
+	if rends == nil {
+		rends = []string{}
+	}
+	return types.JobResp{
+		ID:                  j.ID,
+		VideoID:             j.VideoID,
+		InputKey:            j.InputKey,
+		Pipeline:            j.Pipeline,
+		Options:             j.Options,
+		Status:              string(j.Status),
+		Progress:            j.Progress,
+		ErrorMsg:            j.ErrorMsg,
+		PlaybackReady:       j.PlaybackReady,
+		AvailableRenditions: rends,
+		MasterKey:           j.OutputMasterKey,
+		CreatedAt:           j.CreatedAt,
+		UpdatedAt:           j.UpdatedAt,
+	}
+}
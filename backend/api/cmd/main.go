@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"os"
 
 	"video-encoding/shared/db"
 	"video-encoding/shared/env"
+	"video-encoding/shared/jobwatch"
 	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+	"video-encoding/shared/webhook"
 
 	"time"
 
@@ -13,20 +17,22 @@ import (
 
 	s3_Config "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 )
 
 func main() {
 	cfg := config{
-		addr:         env.GetString("ADDR", ":8080"),
-		apiURL:       env.GetString("EXTERNAL_URL", "localhost:8080"),
-		frontendURL:  env.GetString("FRONTEND_URL", "http://localhost:5173"),
-		producerGRPC: env.GetString("PRODUCER_GRPC_TARGET", "producer:9095"),
+		addr:        env.GetString("ADDR", ":8080"),
+		apiURL:      env.GetString("EXTERNAL_URL", "localhost:8080"),
+		frontendURL: env.GetString("FRONTEND_URL", "http://localhost:5173"),
+		corsOrigins: originList(env.GetString("CORS_ALLOWED_ORIGIN", "http://localhost:3000")),
 
 		db: dbConfig{
 			addr:         env.GetString("DB_ADDR", "postgres://admin:adminpassword@db:5432/REEL_BLOOM?sslmode=disable"),
 			maxOpenConns: env.GetInt("DB_MAX_OPEN_CONNS", 30),
 			maxIdleConns: env.GetInt("DB_MAX_IDLE_CONNS", 30),
 			maxIdleTime:  env.GetString("DB_MAX_IDLE_TIME", "15m"),
+			autoMigrate:  env.GetBool("DB_AUTO_MIGRATE", false),
 		},
 
 		env: env.GetString("ENV", "development"),
@@ -38,12 +44,54 @@ func main() {
 			presignPUTTTL: env.GetDuration("S3_PRESIGN_PUT_TTL", 15*time.Minute),
 			presignGETTTL: env.GetDuration("S3_PRESIGN_GET_TTL", 30*time.Minute),
 		},
+
+		upload: uploadConfig{
+			maxBytes:      int64(env.GetInt("UPLOAD_MAX_BYTES", 50<<30)),
+			maxThumbBytes: int64(env.GetInt("UPLOAD_MAX_THUMB_BYTES", 10<<20)),
+			partSize:      int64(env.GetInt("UPLOAD_PART_SIZE", 64<<20)),
+			autoEnqueue:   env.GetBool("UPLOAD_AUTO_ENQUEUE", false),
+		},
+
+		imports: importConfig{
+			enabled:      env.GetBool("IMPORT_ENABLED", true),
+			interval:     env.GetDuration("IMPORT_INTERVAL", 2*time.Second),
+			timeout:      env.GetDuration("IMPORT_TIMEOUT", 2*time.Hour),
+			allowPrivate: env.GetBool("IMPORT_ALLOW_PRIVATE", false),
+		},
+
+		s3Events: s3EventsConfig{
+			sqsURL:   env.GetString("S3_EVENTS_SQS_URL", ""),
+			token:    env.GetString("S3_EVENTS_TOKEN", ""),
+			snsTopic: env.GetString("S3_EVENTS_SNS_TOPIC_ARN", ""),
+		},
+
+		idempotency: idempotencyConfig{
+			ttl:  env.GetDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+			lock: env.GetDuration("IDEMPOTENCY_LOCK", 2*time.Minute),
+		},
+
+		purge: purgeConfig{
+			enabled:  env.GetBool("PURGE_ENABLED", true),
+			delay:    env.GetDuration("PURGE_DELAY", 30*time.Second),
+			interval: env.GetDuration("PURGE_INTERVAL", 5*time.Second),
+		},
+
+		webhooks: webhookConfig{
+			dispatch:     env.GetBool("WEBHOOK_DISPATCH_ENABLED", true),
+			interval:     env.GetDuration("WEBHOOK_DISPATCH_INTERVAL", 2*time.Second),
+			batch:        env.GetInt("WEBHOOK_DISPATCH_BATCH", 20),
+			maxAttempts:  env.GetInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			allowPrivate: env.GetBool("WEBHOOK_ALLOW_PRIVATE", false),
+		},
 	}
 
 	// Logger
 	logger := zap.Must(zap.NewProduction()).Sugar()
 	defer logger.Sync()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Main Database
 	db, err := db.New(
 		cfg.db.addr,
@@ -58,7 +106,30 @@ func main() {
 	defer db.Close()
 	logger.Info("database connection pool established")
 
-	awsCfg, err := s3_Config.LoadDefaultConfig(context.Background(), s3_Config.WithRegion(cfg.s3.region))
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, db, os.Args[2:], logger); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+
+	// after the migrate command, which needs only DB_ADDR
+	authCfg, err := loadAuthConfig()
+	if err != nil {
+		logger.Fatalw("auth config error", "err", err)
+	}
+	if !authCfg.enabled {
+		logger.Warn("AUTH_ENABLED=false: every request is served as an admin")
+	}
+	cfg.auth = authCfg
+
+	if cfg.db.autoMigrate {
+		if err := migrateUp(ctx, db, logger); err != nil {
+			logger.Fatalw("migrations failed", "err", err)
+		}
+	}
+
+	awsCfg, err := s3_Config.LoadDefaultConfig(ctx, s3_Config.WithRegion(cfg.s3.region))
 	if err != nil {
 		logger.Fatalw("aws config error", "err", err)
 	}
@@ -67,11 +138,46 @@ func main() {
 
 	store := store.NewStorage(db)
 
-	pc, err := NewProducerClient(cfg.producerGRPC)
+	tenants, err := tenant.Parse(env.GetString("TENANTS", ""), tenant.Tenant{
+		Bucket:   cfg.s3.bucket,
+		BasePath: cfg.s3.basePath,
+	})
+	if err != nil {
+		logger.Fatalw("tenant config error", "err", err)
+	}
+	cfg.quotas, err = loadQuotaConfig(env.GetString("QUOTAS_FILE", ""), tenants)
+	if err != nil {
+		logger.Fatalw("quota config error", "err", err)
+	}
+
+	// live progress for /events; every replica listens on its own
+	hub, err := jobwatch.NewHub(cfg.db.addr)
 	if err != nil {
-		logger.Fatalw("producer grpc client init failed", "err", err)
+		logger.Warnw("job notifications disabled, /events falls back to polling", "err", err)
+	} else {
+		defer hub.Close()
+		go hub.Run(ctx)
+	}
+
+	// deliveries are leased in the DB, so every replica can dispatch
+	if cfg.webhooks.dispatch {
+		d := webhook.NewDispatcher(store, logger, cfg.webhooks.allowPrivate)
+		d.Interval = cfg.webhooks.interval
+		d.Batch = cfg.webhooks.batch
+		d.MaxAttempts = cfg.webhooks.maxAttempts
+		go d.Run(ctx)
+	}
+
+	if cfg.purge.enabled {
+		p := &purger{
+			store:    store,
+			s3:       s3Client,
+			tenants:  tenants,
+			log:      logger,
+			interval: cfg.purge.interval,
+		}
+		go p.Run(ctx)
 	}
-	defer pc.Close()
 
 	app := &application{
 		config:    cfg,
@@ -79,10 +185,31 @@ func main() {
 		logger:    logger,
 		s3:        s3Client,
 		s3Presign: presigner,
-		producer: pc,
+		watch:     hub,
+		tenants:   tenants,
+	}
+
+	// imports are leased in the DB, so every replica can ingest
+	if cfg.imports.enabled {
+		in := newIngester(cfg.imports, cfg.upload.maxBytes, store, s3Client, tenants, logger)
+		in.newJob = newJob
+		go in.Run(ctx)
+	}
+
+	go app.sweepIdempotencyKeys(ctx)
+
+	if cfg.s3Events.sqsURL != "" {
+		go app.pollS3Events(ctx, sqs.NewFromConfig(awsCfg))
 	}
 
 	mux := app.mount()
 
+	// the document lists every route; refuse to start when it doesn't
+	doc := openAPIDocument()
+	if err := checkRoutes(mux, doc); err != nil {
+		logger.Fatal(err)
+	}
+	app.openapi = mustOpenAPI(doc)
+
 	logger.Fatal(app.run(mux))
 }
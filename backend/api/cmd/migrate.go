@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"video-encoding/shared/db"
+
+	"go.uber.org/zap"
+)
+
+const migrateUsage = "usage: api migrate [up | down [N] | status]"
+
+// runMigrate implements `api migrate ...`.
+func runMigrate(ctx context.Context, conn *sql.DB, args []string, log *zap.SugaredLogger) error {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		return migrateUp(ctx, conn, log)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down: N must be a positive number\n%s", migrateUsage)
+			}
+			steps = n
+		}
+		reverted, err := db.MigrateDown(ctx, conn, steps)
+		for _, m := range reverted {
+			log.Infow("migration reverted", "version", m.Version, "name", m.Name)
+		}
+		return err
+
+	case "status":
+		all, err := db.MigrationsStatus(ctx, conn)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, m := range all {
+			at := "pending"
+			if m.AppliedAt != nil {
+				at = m.AppliedAt.Format("2006-01-02 15:04:05Z07:00")
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", m.Version, m.Name, at)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", cmd, migrateUsage)
+	}
+}
+
+func migrateUp(ctx context.Context, conn *sql.DB, log *zap.SugaredLogger) error {
+	applied, err := db.MigrateUp(ctx, conn)
+	for _, m := range applied {
+		log.Infow("migration applied", "version", m.Version, "name", m.Name)
+	}
+	if err == nil && len(applied) == 0 {
+		log.Info("schema is up to date")
+	}
+	return err
+}
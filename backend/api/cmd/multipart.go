@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/go-chi/chi"
+)
+
+// S3 multipart limits.
+const (
+	minPartSize      = 5 << 20
+	maxParts         = 10000
+	maxPresignParts  = 100 // part URLs per request
+	partSizeRounding = 1 << 20
+)
+
+// StartMultipartUpload creates a pending_upload video whose input is
+// uploaded in parts; the thumbnail still gets a single presigned PUT.
+func (app *application) StartMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	var req types.StartMultipartUploadReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+
+	v, err := app.newUploadVideo(r.Context(), &req.PresignVideoUploadReq)
+	if err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	if req.SizeBytes < 0 || req.SizeBytes > app.config.upload.maxBytes {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", fmt.Sprintf("sizeBytes must be at most %d", app.config.upload.maxBytes))
+		return
+	}
+	if !app.reserveUpload(w, r, v.ID) {
+		return
+	}
+	partSize := app.partSizeFor(req.SizeBytes)
+
+	mu, err := app.s3.CreateMultipartUpload(r.Context(), &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(app.tenantOf(r.Context()).Bucket),
+		Key:         aws.String(v.InputKey),
+		ContentType: aws.String(req.VideoType),
+	})
+	if err != nil {
+		app.logger.Errorw("create multipart upload failed", "err", err)
+		httpx.Fail(w, 500, "PRESIGN_FAILED", err.Error())
+		return
+	}
+
+	v.UploadState = store.UploadInProgress
+	v.UploadID = mu.UploadId
+	v.UploadPartSize = &partSize
+	if err := app.store.Video.Create(r.Context(), v); err != nil {
+		app.logger.Errorw("video create failed", "err", err)
+		app.abortMultipart(context.WithoutCancel(r.Context()), v.InputKey, aws.ToString(mu.UploadId))
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	thumbPutURL, err := app.PresignPut(r.Context(), v.ThumbnailKey, req.ThumbType)
+	if err != nil {
+		app.logger.Errorw("presign thumb put failed", "err", err)
+		httpx.Fail(w, 500, "PRESIGN_FAILED", err.Error())
+		return
+	}
+
+	out := types.StartMultipartUploadResp{
+		VideoID:     v.ID,
+		VideoKey:    v.InputKey,
+		UploadID:    aws.ToString(mu.UploadId),
+		PartSize:    partSize,
+		ThumbKey:    v.ThumbnailKey,
+		ThumbPutURL: thumbPutURL,
+	}
+	if req.SizeBytes > 0 {
+		out.PartCount = int((req.SizeBytes + partSize - 1) / partSize)
+	}
+	httpx.Created(w, "multipart upload started", out)
+}
+
+// PresignUploadParts returns presigned PUT URLs for the requested parts.
+// The client keeps each part's ETag response header for completion.
+func (app *application) PresignUploadParts(w http.ResponseWriter, r *http.Request) {
+	v, ok := app.loadMultipart(w, r)
+	if !ok {
+		return
+	}
+	if v.UploadState != store.UploadInProgress {
+		httpx.Fail(w, 409, "UPLOAD_NOT_IN_PROGRESS", "upload is "+string(v.UploadState))
+		return
+	}
+
+	var req types.PresignPartsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+	if len(req.Parts) == 0 || len(req.Parts) > maxPresignParts {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", fmt.Sprintf("parts must list 1 to %d part numbers", maxPresignParts))
+		return
+	}
+
+	out := types.PresignPartsResp{Parts: make([]types.PartURLResp, 0, len(req.Parts))}
+	seen := map[int32]bool{}
+	for _, n := range req.Parts {
+		if n < 1 || n > maxParts {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", fmt.Sprintf("part number %d is not within 1-%d", n, maxParts))
+			return
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		ps, err := app.s3Presign.PresignUploadPart(r.Context(), &s3.UploadPartInput{
+			Bucket:     aws.String(app.tenantOf(r.Context()).Bucket),
+			Key:        aws.String(v.InputKey),
+			UploadId:   v.UploadID,
+			PartNumber: aws.Int32(n),
+		}, func(po *s3.PresignOptions) {
+			po.Expires = app.config.s3.presignPUTTTL
+		})
+		if err != nil {
+			httpx.Fail(w, 500, "PRESIGN_FAILED", err.Error())
+			return
+		}
+		out.Parts = append(out.Parts, types.PartURLResp{PartNumber: n, URL: ps.URL})
+	}
+
+	httpx.Ok(w, "part urls", out)
+}
+
+// GetMultipartUpload lists the parts S3 already has, so a client can
+// resume after a network drop by uploading only the missing ones.
+func (app *application) GetMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	v, ok := app.loadMultipart(w, r)
+	if !ok {
+		return
+	}
+
+	out := multipartResp(v)
+	if v.UploadState == store.UploadInProgress {
+		parts, err := app.listParts(r.Context(), v)
+		if err != nil {
+			httpx.Fail(w, 500, "S3_ERROR", err.Error())
+			return
+		}
+		for _, p := range parts {
+			out.Parts = append(out.Parts, types.UploadedPartResp{
+				PartNumber: aws.ToInt32(p.PartNumber),
+				ETag:       aws.ToString(p.ETag),
+				SizeBytes:  aws.ToInt64(p.Size),
+			})
+		}
+	}
+
+	httpx.Ok(w, "multipart upload", out)
+}
+
+// CompleteMultipartUpload assembles the parts and then verifies and
+// completes the video like CompleteUpload. It is safe to retry.
+func (app *application) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	v, ok := app.loadMultipart(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.CompleteMultipartUploadReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+			return
+		}
+	}
+	if err := req.Options.Validate(); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	switch v.UploadState {
+	case store.UploadAborted:
+		httpx.Fail(w, 409, "UPLOAD_ABORTED", "the upload was aborted")
+		return
+	case store.UploadCompleted:
+		// a retry after the parts were assembled
+		app.finishUpload(w, r, v, req.CompleteUploadReq)
+		return
+	}
+
+	parts := make([]s3types.CompletedPart, 0, len(req.Parts))
+	for _, p := range req.Parts {
+		parts = append(parts, s3types.CompletedPart{PartNumber: aws.Int32(p.PartNumber), ETag: aws.String(p.ETag)})
+	}
+	if len(parts) == 0 {
+		uploaded, err := app.listParts(r.Context(), v)
+		if err != nil {
+			httpx.Fail(w, 500, "S3_ERROR", err.Error())
+			return
+		}
+		for _, p := range uploaded {
+			parts = append(parts, s3types.CompletedPart{PartNumber: p.PartNumber, ETag: p.ETag})
+		}
+	}
+	if len(parts) == 0 {
+		httpx.Fail(w, 409, "UPLOAD_INCOMPLETE", "no parts uploaded")
+		return
+	}
+	sort.Slice(parts, func(i, j int) bool { return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber) })
+
+	_, err := app.s3.CompleteMultipartUpload(r.Context(), &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(app.tenantOf(r.Context()).Bucket),
+		Key:             aws.String(v.InputKey),
+		UploadId:        v.UploadID,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.ErrorCode() {
+			case "InvalidPart", "InvalidPartOrder", "EntityTooSmall", "NoSuchUpload":
+				httpx.Fail(w, 422, "UPLOAD_INVALID", apiErr.ErrorMessage())
+				return
+			}
+		}
+		httpx.Fail(w, 500, "S3_ERROR", err.Error())
+		return
+	}
+
+	if err := app.store.Video.SetUploadState(r.Context(), v.ID, store.UploadInProgress, store.UploadCompleted); err != nil && !errors.Is(err, store.ErrConflict) {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+	v.UploadState = store.UploadCompleted
+
+	app.finishUpload(w, r, v, req.CompleteUploadReq)
+}
+
+// AbortMultipartUpload discards the uploaded parts. The video stays
+// pending_upload; delete it to remove it.
+func (app *application) AbortMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	v, ok := app.loadMultipart(w, r)
+	if !ok {
+		return
+	}
+	if v.UploadState != store.UploadInProgress {
+		httpx.Fail(w, 409, "UPLOAD_NOT_IN_PROGRESS", "upload is "+string(v.UploadState))
+		return
+	}
+
+	if err := app.abortMultipart(r.Context(), v.InputKey, aws.ToString(v.UploadID)); err != nil {
+		httpx.Fail(w, 500, "S3_ERROR", err.Error())
+		return
+	}
+	if err := app.store.Video.SetUploadState(r.Context(), v.ID, store.UploadInProgress, store.UploadAborted); err != nil && !errors.Is(err, store.ErrConflict) {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+	v.UploadState = store.UploadAborted
+
+	httpx.Ok(w, "multipart upload aborted", multipartResp(v))
+}
+
+// loadMultipart loads the video of the request and fails unless it was
+// uploaded with a multipart upload.
+func (app *application) loadMultipart(w http.ResponseWriter, r *http.Request) (store.Video, bool) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return store.Video{}, false
+	}
+
+	v, err := app.ownedVideo(r.Context(), videoID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return store.Video{}, false
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return store.Video{}, false
+	}
+	if v.UploadID == nil {
+		httpx.Fail(w, 404, "NOT_FOUND", "video has no multipart upload")
+		return store.Video{}, false
+	}
+	return v, true
+}
+
+func (app *application) listParts(ctx context.Context, v store.Video) ([]s3types.Part, error) {
+	var out []s3types.Part
+	pages := s3.NewListPartsPaginator(app.s3, &s3.ListPartsInput{
+		Bucket:   aws.String(app.tenantOf(ctx).Bucket),
+		Key:      aws.String(v.InputKey),
+		UploadId: v.UploadID,
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page.Parts...)
+	}
+	return out, nil
+}
+
+// abortMultipart aborts an upload; one that is already gone is not an error.
+func (app *application) abortMultipart(ctx context.Context, key, uploadID string) error {
+	_, err := app.s3.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(app.tenantOf(ctx).Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	var nsu *s3types.NoSuchUpload
+	if errors.As(err, &nsu) {
+		return nil
+	}
+	return err
+}
+
+// partSizeFor returns the configured part size, grown so that size fits
+// in maxParts parts.
+func (app *application) partSizeFor(size int64) int64 {
+	ps := max(app.config.upload.partSize, minPartSize)
+	if need := (size + maxParts - 1) / maxParts; need > ps {
+		ps = (need + partSizeRounding - 1) / partSizeRounding * partSizeRounding
+	}
+	return ps
+}
+
+func multipartResp(v store.Video) types.MultipartUploadResp {
+	return types.MultipartUploadResp{
+		VideoID:  v.ID,
+		UploadID: aws.ToString(v.UploadID),
+		State:    string(v.UploadState),
+		PartSize: aws.ToInt64(v.UploadPartSize),
+		Parts:    []types.UploadedPartResp{},
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"video-encoding/shared/openapi"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/types"
+
+	"github.com/go-chi/chi"
+)
+
+// apiOperation describes one route for the OpenAPI document. Every route
+// the router serves must be listed here; checkRoutes enforces it at start.
+type apiOperation struct {
+	method  string
+	path    string // chi pattern, e.g. /v1/videos/{id}
+	id      string // operationId, also the client method name
+	summary string
+	tag     string
+
+	public  bool                // no API key or token needed
+	query   []openapi.Parameter // path parameters are derived from path
+	headers []openapi.Parameter
+	req     any // request body, nil without one
+
+	status int
+	resp   any    // data of the response envelope, nil without data
+	raw    bool   // resp is the body itself, not wrapped in the envelope
+	stream string // content type of a streamed response
+}
+
+func queryParam(name, typ, desc string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: desc, Schema: &openapi.Schema{Type: typ}}
+}
+
+var (
+	idempotencyKeyHeader = openapi.Parameter{
+		Name: "Idempotency-Key", In: "header", Schema: openapi.String(),
+		Description: "retries with the same key replay the first response",
+	}
+	pageParams = []openapi.Parameter{
+		queryParam("limit", "integer", "page size"),
+		queryParam("offset", "integer", "items to skip"),
+	}
+)
+
+var apiOperations = []apiOperation{
+	{method: "GET", path: "/v1/openapi.json", id: "GetOpenAPI", summary: "This document", tag: "meta",
+		public: true, status: 200, resp: map[string]any{}, raw: true},
+	{method: "POST", path: "/v1/ingest/s3-events", id: "S3Events", summary: "S3 ObjectCreated notifications (MinIO webhook or SNS)", tag: "ingest",
+		public: true, query: []openapi.Parameter{queryParam("token", "string", "S3_EVENTS_TOKEN, or send it as a Bearer token")},
+		req: map[string]any{}, status: 200},
+
+	{method: "GET", path: "/v1/videos", id: "ListVideos", summary: "List and search videos", tag: "videos",
+		query: []openapi.Parameter{
+			queryParam("q", "string", "full-text search in title and description"),
+			queryParam("status", "string", "comma-separated video statuses"),
+			queryParam("sort", "string", "created_at, -created_at, title, -title, size or -size"),
+			queryParam("limit", "integer", "page size, up to 100"),
+			queryParam("cursor", "string", "nextCursor of the previous page"),
+			queryParam("includeTotal", "boolean", "also count all matches"),
+			queryParam("owner", "string", "admins only: videos of this owner"),
+		},
+		status: 200, resp: types.VideoListResp{}},
+	{method: "POST", path: "/v1/videos/presign", id: "PresignVideoUpload", summary: "Create a video and presigned upload URLs", tag: "uploads",
+		headers: []openapi.Parameter{idempotencyKeyHeader}, req: types.PresignVideoUploadReq{}, status: 201, resp: types.PresignVideoUploadResp{}},
+	{method: "POST", path: "/v1/videos/{id}/complete", id: "CompleteUpload", summary: "Verify the upload and optionally enqueue a job", tag: "uploads",
+		req: types.CompleteUploadReq{}, status: 200, resp: types.CompleteUploadResp{}},
+	{method: "POST", path: "/v1/videos/multipart", id: "StartMultipartUpload", summary: "Create a video with a resumable multipart upload", tag: "uploads",
+		req: types.StartMultipartUploadReq{}, status: 201, resp: types.StartMultipartUploadResp{}},
+	{method: "GET", path: "/v1/videos/{id}/multipart", id: "GetMultipartUpload", summary: "Parts uploaded so far", tag: "uploads",
+		status: 200, resp: types.MultipartUploadResp{}},
+	{method: "POST", path: "/v1/videos/{id}/multipart/parts", id: "PresignUploadParts", summary: "Presigned URLs for parts", tag: "uploads",
+		req: types.PresignPartsReq{}, status: 200, resp: types.PresignPartsResp{}},
+	{method: "POST", path: "/v1/videos/{id}/multipart/complete", id: "CompleteMultipartUpload", summary: "Assemble the parts and complete the upload", tag: "uploads",
+		req: types.CompleteMultipartUploadReq{}, status: 200, resp: types.CompleteUploadResp{}},
+	{method: "DELETE", path: "/v1/videos/{id}/multipart", id: "AbortMultipartUpload", summary: "Discard the uploaded parts", tag: "uploads",
+		status: 200, resp: types.MultipartUploadResp{}},
+	{method: "POST", path: "/v1/videos/import", id: "ImportVideo", summary: "Create a video imported from a URL", tag: "uploads",
+		req: types.ImportVideoReq{}, status: 202, resp: types.ImportVideoResp{}},
+	{method: "GET", path: "/v1/videos/{id}/import", id: "GetVideoImport", summary: "Progress of an import", tag: "uploads",
+		status: 200, resp: types.ImportResp{}},
+
+	{method: "GET", path: "/v1/videos/{id}", id: "GetVideo", summary: "Get a video; the ETag goes in If-Match of updates", tag: "videos",
+		status: 200, resp: types.VideoResp{}},
+	{method: "PATCH", path: "/v1/videos/{id}", id: "UpdateVideo", summary: "Change metadata (If-Match or updatedAt required)", tag: "videos",
+		headers: []openapi.Parameter{{Name: "If-Match", In: "header", Schema: openapi.String(), Description: "ETag of GET /v1/videos/{id}"}},
+		req:     types.UpdateVideoReq{}, status: 200, resp: types.UpdateVideoResp{}},
+	{method: "DELETE", path: "/v1/videos/{id}", id: "DeleteVideo", summary: "Delete a video and schedule the purge of its objects", tag: "videos",
+		status: 202, resp: types.PurgeResp{}},
+	{method: "GET", path: "/v1/videos/{id}/purge", id: "GetVideoPurge", summary: "Progress of a purge", tag: "videos",
+		status: 200, resp: types.PurgeResp{}},
+	{method: "GET", path: "/v1/videos/{id}/playback", id: "GetVideoPlayback", summary: "Playback state with a signed master playlist URL", tag: "videos",
+		status: 200, resp: types.PlaybackResp{}},
+	{method: "GET", path: "/v1/videos/{id}/events", id: "VideoEvents", summary: "Playback updates as Server-Sent Events, or a WebSocket on Upgrade", tag: "videos",
+		query:  []openapi.Parameter{queryParam("access_token", "string", "JWT or API key, for clients that can't set headers (EventSource); only accepted here")},
+		status: 200, resp: types.PlaybackResp{}, stream: "text/event-stream"},
+
+	{method: "GET", path: "/v1/videos/{id}/jobs", id: "ListVideoJobs", summary: "Jobs of a video, newest first", tag: "jobs",
+		query: pageParams, status: 200, resp: types.JobListResp{}},
+	{method: "POST", path: "/v1/videos/{id}/jobs", id: "CreateVideoJob", summary: "Enqueue a transcode job", tag: "jobs",
+		headers: []openapi.Parameter{idempotencyKeyHeader}, req: types.CreateVideoJobReq{}, status: 201, resp: types.CreateVideoJobResp{}},
+	{method: "GET", path: "/v1/jobs/{jobId}", id: "GetJob", summary: "A job with its renditions and timeline", tag: "jobs",
+		status: 200, resp: types.JobDetailResp{}},
+
+	{method: "GET", path: "/v1/usage", id: "GetUsage", summary: "Usage against quotas", tag: "usage",
+		status: 200, resp: types.UsageResp{}},
+
+	{method: "POST", path: "/v1/webhooks", id: "CreateWebhook", summary: "Register a webhook (operators)", tag: "webhooks",
+		req: types.CreateWebhookReq{}, status: 201, resp: types.WebhookResp{}},
+	{method: "GET", path: "/v1/webhooks", id: "ListWebhooks", summary: "List webhooks (operators)", tag: "webhooks",
+		status: 200, resp: []types.WebhookResp{}},
+	{method: "GET", path: "/v1/webhooks/{id}", id: "GetWebhook", summary: "Get a webhook (operators)", tag: "webhooks",
+		status: 200, resp: types.WebhookResp{}},
+	{method: "PATCH", path: "/v1/webhooks/{id}", id: "UpdateWebhook", summary: "Change a webhook (operators)", tag: "webhooks",
+		req: types.UpdateWebhookReq{}, status: 200, resp: types.WebhookResp{}},
+	{method: "DELETE", path: "/v1/webhooks/{id}", id: "DeleteWebhook", summary: "Delete a webhook (operators)", tag: "webhooks",
+		status: 200, resp: types.DeleteWebhookResp{}},
+	{method: "GET", path: "/v1/webhooks/{id}/deliveries", id: "ListWebhookDeliveries", summary: "Recent deliveries with their attempts (operators)", tag: "webhooks",
+		query: []openapi.Parameter{queryParam("limit", "integer", "deliveries to return")}, status: 200, resp: []types.WebhookDeliveryResp{}},
+}
+
+var pathParamRe = regexp.MustCompile(`\{([^}]+)\}`)
+
+// openAPIDocument builds the document from apiOperations and the types
+// the handlers encode.
+func openAPIDocument() openapi.Document {
+	doc := openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Video encoding API",
+			Version:     "1",
+			Description: "Every JSON response is wrapped in {success, message, data} or {success, error: {code, details}}.",
+		},
+		Security: []openapi.SecurityRequirement{{"apiKey": {}}, {"bearer": {}}},
+		Paths:    map[string]openapi.PathItem{},
+		Components: openapi.Components{
+			Schemas: map[string]*openapi.Schema{},
+			SecuritySchemes: map[string]openapi.SecurityScheme{
+				"apiKey": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+				"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	c := &doc.Components
+	c.Schemas["Error"] = &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"success": {Type: "boolean"},
+			"error":   c.SchemaOf(httpx.APIError{}),
+		},
+		Required: []string{"success", "error"},
+	}
+
+	for _, o := range apiOperations {
+		op := &openapi.Operation{
+			OperationID: o.id,
+			Summary:     o.summary,
+			Tags:        []string{o.tag},
+			Responses: map[string]openapi.Response{
+				"default": {
+					Description: "error",
+					Content:     jsonContent(&openapi.Schema{Ref: "#/components/schemas/Error"}),
+				},
+			},
+		}
+		if o.public {
+			op.Security = &[]openapi.SecurityRequirement{}
+		}
+
+		for _, m := range pathParamRe.FindAllStringSubmatch(o.path, -1) {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: m[1], In: "path", Required: true, Schema: openapi.String()})
+		}
+		op.Parameters = append(op.Parameters, o.query...)
+		op.Parameters = append(op.Parameters, o.headers...)
+
+		if o.req != nil {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: jsonContent(c.SchemaOf(o.req))}
+		}
+
+		body := envelope(c, o.resp)
+		if o.raw {
+			body = c.SchemaOf(o.resp)
+		}
+		resp := openapi.Response{Description: http.StatusText(o.status), Content: jsonContent(body)}
+		if o.stream != "" {
+			// each event's data is the schema, not an envelope
+			resp.Content = map[string]openapi.MediaType{o.stream: {Schema: c.SchemaOf(o.resp)}}
+		}
+		op.Responses[fmt.Sprint(o.status)] = resp
+
+		item := doc.Paths[o.path]
+		if item == nil {
+			item = openapi.PathItem{}
+			doc.Paths[o.path] = item
+		}
+		item[strings.ToLower(o.method)] = op
+	}
+	return doc
+}
+
+func jsonContent(s *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{"application/json": {Schema: s}}
+}
+
+// envelope is the schema of httpx.APIResponse carrying data.
+func envelope(c *openapi.Components, data any) *openapi.Schema {
+	s := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"success": {Type: "boolean"},
+			"message": openapi.String(),
+		},
+		Required: []string{"success"},
+	}
+	if data != nil {
+		s.Properties["data"] = c.SchemaOf(data)
+		s.Required = append(s.Required, "data")
+	}
+	return s
+}
+
+// ServeOpenAPI serves the document built at startup.
+func (app *application) ServeOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(app.openapi)
+}
+
+// checkRoutes fails unless the router serves exactly the operations of
+// doc, so a route can't be added or removed without documenting it.
+func checkRoutes(r chi.Routes, doc openapi.Document) error {
+	documented := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var undocumented []string
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// Route("/x") with Get("/") shows up as /x/
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		key := method + " " + route
+		if documented[key] {
+			delete(documented, key)
+			return nil
+		}
+		undocumented = append(undocumented, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for key := range documented {
+		missing = append(missing, key)
+	}
+	if len(undocumented) == 0 && len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(undocumented)
+	sort.Strings(missing)
+	return fmt.Errorf("openapi out of sync with the router: undocumented routes %v, documented but not routed %v", undocumented, missing)
+}
+
+// mustOpenAPI encodes the document once; it never changes at runtime.
+func mustOpenAPI(doc openapi.Document) []byte {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.uber.org/zap"
+)
+
+const (
+	purgeBatchSize   = 1000 // DeleteObjects limit
+	purgeMaxAttempts = 10
+	purgeLease       = 10 * time.Minute
+)
+
+// purger deletes the S3 objects of deleted videos. Purges are leased in the
+// DB, so every API replica can run one.
+type purger struct {
+	store    store.Storage
+	s3       *s3.Client
+	tenants  *tenant.Registry
+	log      *zap.SugaredLogger
+	interval time.Duration
+}
+
+func (p *purger) Run(ctx context.Context) {
+	for {
+		batch, err := p.store.Purge.Claim(ctx, 5, purgeLease)
+		if err != nil && ctx.Err() == nil {
+			p.log.Warnw("purge claim failed", "err", err)
+		}
+
+		for _, pg := range batch {
+			p.purge(ctx, pg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.interval):
+		}
+	}
+}
+
+func (p *purger) purge(ctx context.Context, pg store.Purge) {
+	log := p.log.With("videoId", pg.VideoID, "tenant", pg.TenantID, "attempt", pg.Attempts)
+
+	t, err := p.tenants.Get(pg.TenantID)
+	for _, prefix := range pg.Prefixes {
+		if err == nil {
+			err = p.deletePrefix(ctx, t.Bucket, pg.VideoID, prefix)
+		}
+		if err != nil {
+			log.Warnw("purge failed", "prefix", prefix, "err", err)
+
+			var retryAt *time.Time
+			if pg.Attempts < purgeMaxAttempts {
+				t := time.Now().Add(purgeBackoff(pg.Attempts))
+				retryAt = &t
+			}
+			if err := p.store.Purge.Fail(context.WithoutCancel(ctx), pg.VideoID, err.Error(), retryAt); err != nil {
+				log.Errorw("purge status not saved", "err", err)
+			}
+			return
+		}
+	}
+
+	if err := p.store.Purge.Complete(ctx, pg.VideoID); err != nil {
+		log.Errorw("purge status not saved", "err", err)
+		return
+	}
+	log.Infow("purge completed")
+}
+
+// deletePrefix lists and deletes everything under prefix in batches. It is
+// safe to repeat: a retry simply finds fewer objects.
+func (p *purger) deletePrefix(ctx context.Context, bucket, videoID, prefix string) error {
+	pages := s3.NewListObjectsV2Paginator(p.s3, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(purgeBatchSize),
+	})
+
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list %s: %w", prefix, err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		ids := make([]s3types.ObjectIdentifier, 0, len(page.Contents))
+		for _, o := range page.Contents {
+			ids = append(ids, s3types.ObjectIdentifier{Key: o.Key})
+		}
+
+		out, err := p.s3.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3types.Delete{Objects: ids, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("delete under %s: %w", prefix, err)
+		}
+
+		deleted := len(ids) - len(out.Errors)
+		if err := p.store.Purge.AddDeleted(ctx, videoID, deleted); err != nil {
+			return err
+		}
+		if len(out.Errors) > 0 {
+			e := out.Errors[0]
+			return fmt.Errorf("delete %s: %s", aws.ToString(e.Key), aws.ToString(e.Message))
+		}
+	}
+	return nil
+}
+
+// purgeBackoff grows 30s, 1m, 2m, ... capped at 30m.
+func purgeBackoff(attempt int) time.Duration {
+	d := 30 * time.Second
+	for i := 1; i < attempt && d < 30*time.Minute; i++ {
+		d *= 2
+	}
+	if d > 30*time.Minute {
+		d = 30 * time.Minute
+	}
+	return d
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"video-encoding/shared/auth"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+	"video-encoding/shared/types"
+)
+
+// quotaLimits caps what a tenant, or one caller in it, may consume. Zero
+// means unlimited.
+type quotaLimits struct {
+	UploadsPerHour         int64 `json:"uploadsPerHour"`
+	ConcurrentJobs         int64 `json:"concurrentJobs"`
+	StoredBytes            int64 `json:"storedBytes"`
+	EncodedMinutesPerMonth int64 `json:"encodedMinutesPerMonth"`
+}
+
+type tenantQuota struct {
+	quotaLimits
+	// Subjects narrows single callers of the tenant, e.g. "key:acme/ingest".
+	Subjects map[string]quotaLimits `json:"subjects"`
+}
+
+// quotaConfig is the QUOTAS_FILE. A tenant entry replaces Defaults.
+type quotaConfig struct {
+	Defaults quotaLimits            `json:"defaults"`
+	Tenants  map[string]tenantQuota `json:"tenants"`
+}
+
+func loadQuotaConfig(path string, tenants *tenant.Registry) (quotaConfig, error) {
+	var cfg quotaConfig
+	if path == "" {
+		return cfg, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("quotas %s: %w", path, err)
+	}
+	for id := range cfg.Tenants {
+		if _, err := tenants.Get(id); err != nil {
+			return cfg, fmt.Errorf("quotas %s: %w", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// limitsFor returns the limits of tenantID and, if it has its own, of
+// subject.
+func (c quotaConfig) limitsFor(tenantID, subject string) (quotaLimits, *quotaLimits) {
+	t, ok := c.Tenants[tenantID]
+	if !ok {
+		return c.Defaults, nil
+	}
+	if s, ok := t.Subjects[subject]; ok && subject != "" {
+		return t.quotaLimits, &s
+	}
+	return t.quotaLimits, nil
+}
+
+// quotaKind is what a request is about to consume.
+type quotaKind int
+
+const (
+	quotaUpload quotaKind = iota // a new video
+	quotaJob                     // a new job
+)
+
+// quotaExceeded is a limit the caller is at.
+type quotaExceeded struct {
+	code       string
+	msg        string
+	retryAfter time.Duration // 0: no telling when
+}
+
+// monthStart is the start of the UTC month of t; encoded minutes reset then.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkQuota measures the caller's tenant, and the caller if it has
+// limits of its own, against what kind consumes. Checks and inserts are not
+// atomic, so concurrent requests can overshoot a limit by a few; uploads
+// per hour are exact, see reserveUpload.
+func (app *application) checkQuota(ctx context.Context, kind quotaKind) (*quotaExceeded, error) {
+	p := auth.FromContext(ctx)
+	tenantID, _ := tenant.FromContext(ctx)
+	tl, sl := app.config.quotas.limitsFor(tenantID, p.Subject)
+
+	type scope struct {
+		who    string
+		limits quotaLimits
+		usage  store.UsageScope
+	}
+	scopes := []scope{{"tenant", tl, store.UsageScope{TenantID: tenantID}}}
+	if sl != nil {
+		scopes = append(scopes, scope{"caller", *sl, store.UsageScope{TenantID: tenantID, Owner: &p.Subject}})
+	}
+
+	now := time.Now()
+	for _, s := range scopes {
+		if s.limits == (quotaLimits{}) {
+			continue
+		}
+		u, err := app.store.Usage.Get(ctx, s.usage, now, monthStart(now))
+		if err != nil {
+			return nil, err
+		}
+		if e := s.limits.exceeded(kind, u, now); e != nil {
+			e.msg = s.who + " " + e.msg
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (l quotaLimits) exceeded(kind quotaKind, u store.Usage, now time.Time) *quotaExceeded {
+	switch kind {
+	case quotaUpload:
+		if l.StoredBytes > 0 && u.StoredBytes >= l.StoredBytes {
+			return &quotaExceeded{code: "QUOTA_STORAGE", msg: fmt.Sprintf("stores %d of %d bytes allowed; delete videos to free space", u.StoredBytes, l.StoredBytes)}
+		}
+	case quotaJob:
+		if l.ConcurrentJobs > 0 && u.ActiveJobs >= l.ConcurrentJobs {
+			return &quotaExceeded{code: "QUOTA_CONCURRENT_JOBS", msg: fmt.Sprintf("has %d of %d jobs allowed queued or processing", u.ActiveJobs, l.ConcurrentJobs), retryAfter: 30 * time.Second}
+		}
+		if l.EncodedMinutesPerMonth > 0 && encodedMinutes(u) >= l.EncodedMinutesPerMonth {
+			return &quotaExceeded{
+				code:       "QUOTA_ENCODED_MINUTES",
+				msg:        fmt.Sprintf("encoded %d of %d minutes allowed this month", encodedMinutes(u), l.EncodedMinutesPerMonth),
+				retryAfter: monthStart(now).AddDate(0, 1, 0).Sub(now),
+			}
+		}
+	}
+	return nil
+}
+
+func encodedMinutes(u store.Usage) int64 {
+	return int64(math.Ceil(u.EncodedSeconds / 60))
+}
+
+// allowQuota answers 429 and returns false when the caller is over quota
+// for kind.
+func (app *application) allowQuota(w http.ResponseWriter, r *http.Request, kind quotaKind) bool {
+	e, err := app.checkQuota(r.Context(), kind)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return false
+	}
+	if e == nil {
+		return true
+	}
+	if e.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(e.retryAfter.Seconds()))))
+	}
+	httpx.Fail(w, 429, e.code, e.msg)
+	return false
+}
+
+// reserveUpload checks the storage quota and records videoID as an upload
+// of the caller, answering 429 when the tenant or the caller already
+// created its videos of the hour. The upload counts for an hour even if
+// the video is deleted, so deleting doesn't buy more uploads.
+func (app *application) reserveUpload(w http.ResponseWriter, r *http.Request, videoID string) bool {
+	if !app.allowQuota(w, r, quotaUpload) {
+		return false
+	}
+
+	ctx := r.Context()
+	p := auth.FromContext(ctx)
+	tenantID, _ := tenant.FromContext(ctx)
+	tl, sl := app.config.quotas.limitsFor(tenantID, p.Subject)
+
+	limits := []store.UploadLimit{{Scope: store.UsageScope{TenantID: tenantID}, PerHour: tl.UploadsPerHour}}
+	if sl != nil {
+		limits = append(limits, store.UploadLimit{Scope: store.UsageScope{TenantID: tenantID, Owner: &p.Subject}, PerHour: sl.UploadsPerHour})
+	}
+
+	now := time.Now()
+	err := app.store.Usage.ReserveUpload(ctx, tenantID, ownerOf(ctx), videoID, limits, now)
+	var le *store.UploadLimitError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &le):
+		who := "tenant"
+		if le.Limit.Scope.Owner != nil {
+			who = "caller"
+		}
+		if !le.Oldest.IsZero() {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(le.Oldest.Add(time.Hour).Sub(now).Seconds()))))
+		}
+		httpx.Fail(w, 429, "QUOTA_UPLOADS_PER_HOUR", who+" "+le.Error())
+	default:
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+	}
+	return false
+}
+
+// GetUsage shows the usage of the caller's tenant, and of the caller if it
+// has limits of its own, against their limits.
+func (app *application) GetUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p := auth.FromContext(ctx)
+	tenantID, _ := tenant.FromContext(ctx)
+	tl, sl := app.config.quotas.limitsFor(tenantID, p.Subject)
+
+	now := time.Now()
+	u, err := app.store.Usage.Get(ctx, store.UsageScope{TenantID: tenantID}, now, monthStart(now))
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+	out := types.UsageResp{
+		TenantID:    tenantID,
+		Subject:     p.Subject,
+		PeriodStart: monthStart(now),
+		Tenant:      usageResp(u, tl),
+	}
+
+	if sl != nil {
+		u, err := app.store.Usage.Get(ctx, store.UsageScope{TenantID: tenantID, Owner: &p.Subject}, now, monthStart(now))
+		if err != nil {
+			httpx.Fail(w, 500, "DB_ERROR", err.Error())
+			return
+		}
+		caller := usageResp(u, *sl)
+		out.Caller = &caller
+	}
+
+	httpx.Ok(w, "usage", out)
+}
+
+func usageResp(u store.Usage, l quotaLimits) types.QuotaUsage {
+	item := func(used, limit int64) types.UsageItem {
+		out := types.UsageItem{Used: used}
+		if limit > 0 {
+			out.Limit = &limit
+		}
+		return out
+	}
+	return types.QuotaUsage{
+		UploadsLastHour:         item(u.UploadsLastHour, l.UploadsPerHour),
+		ConcurrentJobs:          item(u.ActiveJobs, l.ConcurrentJobs),
+		StoredBytes:             item(u.StoredBytes, l.StoredBytes),
+		EncodedMinutesThisMonth: item(encodedMinutes(u), l.EncodedMinutesPerMonth),
+	}
+}
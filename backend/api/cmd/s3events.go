@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+	"video-encoding/shared/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/google/uuid"
+)
+
+type s3EventsConfig struct {
+	sqsURL   string // poll this queue; empty disables the poller
+	token    string // required by POST /v1/ingest/s3-events; empty disables it
+	snsTopic string // SNS topic whose subscription may be confirmed
+}
+
+// s3Event is an S3 event notification. MinIO webhooks send the same
+// Records, next to fields we don't need.
+type s3Event struct {
+	Records []s3EventRecord `json:"Records"`
+}
+
+type s3EventRecord struct {
+	EventName string `json:"eventName"` // ObjectCreated:Put, s3:ObjectCreated:Put (MinIO)
+	S3        struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"` // URL-encoded
+			Size int64  `json:"size"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// snsEnvelope wraps S3 events published through SNS, both on HTTP
+// subscriptions and on SQS queues without raw message delivery.
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	TopicArn     string `json:"TopicArn"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// parseS3Event accepts a bare S3 event or one wrapped in an SNS envelope.
+// The envelope is returned when it is not a notification.
+func parseS3Event(body []byte) ([]s3EventRecord, *snsEnvelope, error) {
+	var env snsEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, nil, err
+	}
+	switch env.Type {
+	case "":
+	case "Notification":
+		body = []byte(env.Message)
+	default:
+		return nil, &env, nil
+	}
+
+	var ev s3Event
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, nil, err
+	}
+	// s3:TestEvent and other messages have no records
+	return ev.Records, nil, nil
+}
+
+// S3Events receives S3 ObjectCreated notifications from a MinIO webhook
+// target or an SNS HTTP(S) subscription. The token is sent as a bearer
+// token (MinIO auth_token) or as ?token= in the subscription URL.
+func (app *application) S3Events(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if app.config.s3Events.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(app.config.s3Events.token)) != 1 {
+		httpx.Fail(w, 401, "UNAUTHORIZED", "invalid token")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+	records, env, err := parseS3Event(body)
+	if err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+
+	if env != nil {
+		if env.Type == "SubscriptionConfirmation" && env.TopicArn != "" && env.TopicArn == app.config.s3Events.snsTopic {
+			if err := app.confirmSNS(r.Context(), env.SubscribeURL); err != nil {
+				httpx.Fail(w, 502, "SNS_CONFIRM_FAILED", err.Error())
+				return
+			}
+			httpx.Ok(w, "subscription confirmed", nil)
+			return
+		}
+		httpx.Ok(w, "ignored", nil)
+		return
+	}
+
+	for _, rec := range records {
+		if err := app.handleS3Record(r.Context(), rec); err != nil {
+			// the sender retries the whole batch; records already handled are no-ops
+			app.logger.Warnw("s3 event failed", "key", rec.S3.Object.Key, "err", err)
+			httpx.Fail(w, 500, "INGEST_FAILED", err.Error())
+			return
+		}
+	}
+	httpx.Ok(w, "processed", nil)
+}
+
+func (app *application) confirmSNS(ctx context.Context, subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+		return errors.New("unexpected SubscribeURL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("SNS answered " + resp.Status)
+	}
+	return nil
+}
+
+// pollS3Events long-polls the SQS queue S3 (directly or through SNS)
+// publishes to. Messages are deleted once handled; failed ones come back
+// after the queue's visibility timeout.
+func (app *application) pollS3Events(ctx context.Context, client *sqs.Client) {
+	queueURL := app.config.s3Events.sqsURL
+	for ctx.Err() == nil {
+		out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20,
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				app.logger.Warnw("sqs receive failed", "err", err)
+				time.Sleep(5 * time.Second)
+			}
+			continue
+		}
+
+		for _, m := range out.Messages {
+			if err := app.handleS3Message(ctx, aws.ToString(m.Body)); err != nil {
+				app.logger.Warnw("s3 event failed", "messageId", aws.ToString(m.MessageId), "err", err)
+				continue
+			}
+			if _, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(queueURL),
+				ReceiptHandle: m.ReceiptHandle,
+			}); err != nil {
+				app.logger.Warnw("sqs delete failed", "messageId", aws.ToString(m.MessageId), "err", err)
+			}
+		}
+	}
+}
+
+func (app *application) handleS3Message(ctx context.Context, body string) error {
+	records, env, err := parseS3Event([]byte(body))
+	if err != nil {
+		app.logger.Warnw("malformed s3 event dropped", "err", err)
+		return nil
+	}
+	if env != nil {
+		return nil
+	}
+	for _, rec := range records {
+		if err := app.handleS3Record(ctx, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleS3Record completes the upload of the video whose input was just
+// created and enqueues the default pipeline. Both happen in the
+// transaction that moves the video out of pending_upload, so repeated or
+// concurrent notifications, and POST /complete, enqueue at most one job.
+// Only errors worth retrying are returned.
+func (app *application) handleS3Record(ctx context.Context, rec s3EventRecord) error {
+	if !strings.Contains(rec.EventName, "ObjectCreated:") {
+		return nil
+	}
+	key, err := url.QueryUnescape(rec.S3.Object.Key)
+	if err != nil {
+		return nil
+	}
+	// the key's prefix tells the tenant; scoping to it keeps an object in
+	// one tenant's prefix from completing another tenant's video
+	t, ok := app.tenants.ForInput(rec.S3.Bucket.Name, key)
+	if !ok {
+		return nil
+	}
+	videoID, ok := videoIDFromInputKey(t, key)
+	if !ok {
+		return nil
+	}
+	ctx = tenant.NewContext(ctx, t.ID)
+
+	v, err := app.store.Video.Get(ctx, videoID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// multipart uploads are completed by the client, imports by the ingester
+	if v.InputKey != key || v.Status != store.PendingUpload || v.UploadState != store.UploadPending {
+		return nil
+	}
+
+	log := app.logger.With("videoId", v.ID, "key", key)
+
+	// the thumbnail may still be on its way
+	size, contentType, err := app.verifyUpload(ctx, v, false)
+	if err != nil {
+		if errors.Is(err, errUploadMissing) {
+			return err
+		}
+		// POST /complete reports the same error to the client
+		log.Warnw("uploaded input rejected", "err", err)
+		return nil
+	}
+
+	job, msgs, err := newJob(v, types.CreateVideoJobReq{Pipeline: types.PipelineHLS})
+	if err != nil {
+		return err
+	}
+	v, err = completeUpload(ctx, app.store, v.ID, size, contentType, &job, msgs...)
+	if errors.Is(err, store.ErrConflict) || errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Infow("upload completed from s3 event", "jobId", job.ID)
+	return nil
+}
+
+// videoIDFromInputKey maps <tenant base>inputs/<video id>-<filename> back
+// to the video id.
+func videoIDFromInputKey(t tenant.Tenant, key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, t.BasePath+"inputs/")
+	if !ok || len(rest) < 37 || rest[36] != '-' {
+		return "", false
+	}
+	id := rest[:36]
+	if _, err := uuid.Parse(id); err != nil {
+		return "", false
+	}
+	return id, true
+}
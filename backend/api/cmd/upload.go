@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+	"video-encoding/shared/webhook"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/go-chi/chi"
+)
+
+// sniffBytes is how much of an object is read to recognise its format.
+const sniffBytes = 512
+
+type uploadConfig struct {
+	maxBytes      int64
+	maxThumbBytes int64
+	partSize      int64 // multipart uploads
+	autoEnqueue   bool  // default for CompleteUploadReq.Enqueue
+}
+
+// errUploadMissing means an object of the upload is not in S3 (yet).
+var errUploadMissing = errors.New("upload missing")
+
+// CompleteUpload verifies the objects of a pending_upload video and moves
+// it to uploaded, optionally enqueueing the default job. Completing a video
+// that is already uploaded is a no-op, so clients can retry.
+func (app *application) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	var req types.CompleteUploadReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+			return
+		}
+	}
+	if err := req.Options.Validate(); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	v, err := app.ownedVideo(r.Context(), videoID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+	switch v.UploadState {
+	case store.UploadInProgress:
+		httpx.Fail(w, 409, "UPLOAD_INCOMPLETE", "multipart upload in progress; complete it with POST /v1/videos/{id}/multipart/complete")
+		return
+	case store.UploadAborted:
+		httpx.Fail(w, 409, "UPLOAD_ABORTED", "the upload was aborted")
+		return
+	case store.UploadImporting:
+		httpx.Fail(w, 409, "IMPORT_IN_PROGRESS", "the video is imported from a source URL; see GET /v1/videos/{id}/import")
+		return
+	case store.UploadFailed:
+		httpx.Fail(w, 409, "UPLOAD_FAILED", "the upload failed")
+		return
+	}
+
+	app.finishUpload(w, r, v, req)
+}
+
+// finishUpload verifies the objects of v, moves it to uploaded and, if
+// asked to, enqueues its first job.
+func (app *application) finishUpload(w http.ResponseWriter, r *http.Request, v store.Video, req types.CompleteUploadReq) {
+	if req.Pipeline == "" {
+		req.Pipeline = types.PipelineHLS
+	}
+	if err := types.ValidatePipeline(req.Pipeline); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	enqueue := app.config.upload.autoEnqueue
+	if req.Enqueue != nil {
+		enqueue = *req.Enqueue
+	}
+
+	if v.Status != store.PendingUpload {
+		httpx.Ok(w, "upload already completed", types.CompleteUploadResp{Video: app.videoResp(r, v)})
+		return
+	}
+
+	size, contentType, err := app.verifyUpload(r.Context(), v, true)
+	if err != nil {
+		if errors.Is(err, errUploadMissing) {
+			httpx.Fail(w, 409, "UPLOAD_INCOMPLETE", err.Error())
+			return
+		}
+		httpx.Fail(w, 422, "UPLOAD_INVALID", err.Error())
+		return
+	}
+
+	// the job is enqueued in the same transaction, so a retry after a
+	// crash can't complete the upload without it
+	var job *store.Job
+	var msgs []store.OutboxEntry
+	if enqueue {
+		if !app.allowQuota(w, r, quotaJob) {
+			return
+		}
+		j, m, err := newJob(v, types.CreateVideoJobReq{Pipeline: req.Pipeline, Options: req.Options})
+		if err != nil {
+			httpx.Fail(w, 500, "ENCODE_ERROR", err.Error())
+			return
+		}
+		job, msgs = &j, m
+	}
+
+	videoID := v.ID
+	v, err = completeUpload(r.Context(), app.store, videoID, size, contentType, job, msgs...)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+		case errors.Is(err, store.ErrConflict):
+			// a concurrent request completed it first
+			v, err = app.store.Video.Get(r.Context(), videoID)
+			if err != nil {
+				httpx.Fail(w, 500, "DB_ERROR", err.Error())
+				return
+			}
+			httpx.Ok(w, "upload already completed", types.CompleteUploadResp{Video: app.videoResp(r, v)})
+		default:
+			httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		}
+		return
+	}
+
+	out := types.CompleteUploadResp{Video: app.videoResp(r, v)}
+	if job != nil {
+		out.JobID = &job.ID
+	}
+
+	httpx.Ok(w, "upload completed", out)
+}
+
+// verifyUpload verifies the objects of v and returns the input's size and
+// sniffed content type. Without requireThumb a thumbnail that is not
+// uploaded yet is skipped.
+func (app *application) verifyUpload(ctx context.Context, v store.Video, requireThumb bool) (int64, string, error) {
+	size, contentType, err := app.verifyVideoObject(ctx, v.InputKey)
+	if err != nil {
+		return 0, "", err
+	}
+	if v.ThumbnailKey != "" {
+		err := app.verifyThumbObject(ctx, v.ThumbnailKey)
+		if err != nil && (requireThumb || !errors.Is(err, errUploadMissing)) {
+			return 0, "", err
+		}
+	}
+	return size, contentType, nil
+}
+
+// completeUpload completes the upload of a video, enqueues job if set and
+// emits video.uploaded in one transaction, so the webhook fires exactly
+// when the upload completes.
+func completeUpload(ctx context.Context, st store.Storage, id string, size int64, contentType string, job *store.Job, msgs ...store.OutboxEntry) (store.Video, error) {
+	var v store.Video
+	err := st.WithTx(ctx, func(tx store.Storage) error {
+		var err error
+		v, err = tx.Video.CompleteUpload(ctx, id, size, contentType, job, msgs...)
+		if err != nil {
+			return err
+		}
+		return webhook.Emit(ctx, tx, webhook.EventVideoUploaded, webhook.VideoData{
+			VideoID: v.ID,
+			Title:   v.Title,
+			Status:  string(v.Status),
+		})
+	})
+	return v, err
+}
+
+// verifyVideoObject checks that key exists, is within the size limit and
+// starts like a video container. It returns the size and the content type
+// recognised from the bytes.
+func (app *application) verifyVideoObject(ctx context.Context, key string) (int64, string, error) {
+	head, err := app.headObject(ctx, key)
+	if err != nil {
+		return 0, "", fmt.Errorf("video: %w", err)
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	if size <= 0 {
+		return 0, "", errors.New("video: object is empty")
+	}
+	if size > app.config.upload.maxBytes {
+		return 0, "", fmt.Errorf("video: %d bytes exceeds the limit of %d", size, app.config.upload.maxBytes)
+	}
+	if ct := aws.ToString(head.ContentType); ct != "" && !strings.HasPrefix(ct, "video/") && ct != "application/octet-stream" {
+		return 0, "", fmt.Errorf("video: content type %q is not a video", ct)
+	}
+
+	b, err := app.readPrefix(ctx, key)
+	if err != nil {
+		return 0, "", fmt.Errorf("video: %w", err)
+	}
+	ct := sniffVideo(b)
+	if ct == "" {
+		return 0, "", errors.New("video: unrecognised container format")
+	}
+	return size, ct, nil
+}
+
+func (app *application) verifyThumbObject(ctx context.Context, key string) error {
+	head, err := app.headObject(ctx, key)
+	if err != nil {
+		return fmt.Errorf("thumbnail: %w", err)
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	if size <= 0 {
+		return errors.New("thumbnail: object is empty")
+	}
+	if size > app.config.upload.maxThumbBytes {
+		return fmt.Errorf("thumbnail: %d bytes exceeds the limit of %d", size, app.config.upload.maxThumbBytes)
+	}
+
+	b, err := app.readPrefix(ctx, key)
+	if err != nil {
+		return fmt.Errorf("thumbnail: %w", err)
+	}
+	switch ct := http.DetectContentType(b); ct {
+	case "image/jpeg", "image/png", "image/webp", "image/gif":
+		return nil
+	default:
+		return fmt.Errorf("thumbnail: %s is not an image", ct)
+	}
+}
+
+func (app *application) headObject(ctx context.Context, key string) (*s3.HeadObjectOutput, error) {
+	out, err := app.s3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(app.tenantOf(ctx).Bucket),
+		Key:    aws.String(key),
+	})
+	var nf *s3types.NotFound
+	if errors.As(err, &nf) {
+		return nil, fmt.Errorf("%w: %s", errUploadMissing, key)
+	}
+	return out, err
+}
+
+func (app *application) readPrefix(ctx context.Context, key string) ([]byte, error) {
+	out, err := app.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(app.tenantOf(ctx).Bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=0-%d", sniffBytes-1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(io.LimitReader(out.Body, sniffBytes))
+}
+
+// sniffVideo recognises the common video containers by their magic bytes
+// and returns their content type, or "" if b is not one of them.
+func sniffVideo(b []byte) string {
+	switch {
+	case len(b) >= 12 && bytes.Equal(b[4:8], []byte("ftyp")):
+		if bytes.Equal(b[8:10], []byte("qt")) {
+			return "video/quicktime"
+		}
+		return "video/mp4"
+	case bytes.HasPrefix(b, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// EBML: WebM declares its doctype in the header, anything else is Matroska
+		if bytes.Contains(b, []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case len(b) >= 12 && bytes.HasPrefix(b, []byte("RIFF")) && bytes.Equal(b[8:12], []byte("AVI ")):
+		return "video/x-msvideo"
+	case bytes.HasPrefix(b, []byte("FLV")):
+		return "video/x-flv"
+	case bytes.HasPrefix(b, []byte{0x00, 0x00, 0x01, 0xBA}):
+		return "video/mpeg"
+	case len(b) > 188 && b[0] == 0x47 && b[188] == 0x47:
+		// MPEG-TS: sync byte at the start of every 188-byte packet
+		return "video/mp2t"
+	}
+	return ""
+}
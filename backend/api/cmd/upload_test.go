@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSniffVideo(t *testing.T) {
+	ts := make([]byte, 189)
+	ts[0], ts[188] = 0x47, 0x47
+
+	tests := []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{"mp4", []byte("\x00\x00\x00\x20ftypisom\x00\x00\x02\x00"), "video/mp4"},
+		{"quicktime", []byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"), "video/quicktime"},
+		{"webm", append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x84}, "webm"...), "video/webm"},
+		{"matroska", append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x88}, "matroska"...), "video/x-matroska"},
+		{"avi", []byte("RIFF\x00\x00\x00\x00AVI LIST"), "video/x-msvideo"},
+		{"flv", []byte("FLV\x01\x05"), "video/x-flv"},
+		{"mpeg-ps", []byte{0x00, 0x00, 0x01, 0xBA, 0x44}, "video/mpeg"},
+		{"mpeg-ts", ts, "video/mp2t"},
+		{"ts sync byte once", ts[:188], ""},
+		{"short ftyp", []byte("\x00\x00\x00\x20ftyp"), ""},
+		{"wav", []byte("RIFF\x00\x00\x00\x00WAVEfmt "), ""},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), ""},
+		{"html", []byte("<!doctype html><html>"), ""},
+		{"zeros", bytes.Repeat([]byte{0}, 512), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := sniffVideo(tt.b); got != tt.want {
+			t.Errorf("%s: sniffVideo = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"video-encoding/shared/events"
 	httpx "video-encoding/shared/response"
 	"video-encoding/shared/store"
 	"video-encoding/shared/types"
@@ -16,7 +17,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
-	producerpb "video-encoding/shared/proto/job"
 )
 
 func (app *application) GetVideoPlayback(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +26,7 @@ func (app *application) GetVideoPlayback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	v, err := app.store.Video.Get(r.Context(), videoID)
+	v, err := app.ownedVideo(r.Context(), videoID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
 			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
@@ -36,41 +36,54 @@ func (app *application) GetVideoPlayback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	p, err := app.playbackFor(r.Context(), v)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
+			httpx.Fail(w, 404, "NOT_FOUND", "job not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	if p.JobID == nil {
+		httpx.Ok(w, "no job yet", p)
+		return
+	}
+	httpx.Ok(w, "playback", p)
+}
+
+// playbackFor builds the playback view of a video from its latest job.
+func (app *application) playbackFor(ctx context.Context, v store.Video) (types.PlaybackResp, error) {
 	if v.LatestJobID == nil || *v.LatestJobID == "" {
-		httpx.Ok(w, "no job yet", types.PlaybackResp{
-			VideoID:       videoID,
+		return types.PlaybackResp{
+			VideoID:       v.ID,
 			Status:        string(v.Status),
 			Progress:      0,
 			PlaybackReady: false,
-		})
-		return
+		}, nil
 	}
 
-	if app.store.Job == nil {
-		httpx.Fail(w, 500, "NOT_IMPLEMENTED", "Job store not wired (app.store.Job is nil)")
-		return
+	j, err := app.store.Job.Get(ctx, *v.LatestJobID)
+	if err != nil {
+		return types.PlaybackResp{}, err
 	}
 
-	j, err := app.store.Job.Get(r.Context(), *v.LatestJobID)
+	rends, err := app.store.Rendition.ListByJob(ctx, j.ID)
 	if err != nil {
-		if errors.Is(err, store.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
-			httpx.Fail(w, 404, "NOT_FOUND", "job not found")
-			return
-		}
-		httpx.Fail(w, 500, "DB_ERROR", err.Error())
-		return
+		return types.PlaybackResp{}, err
 	}
 
 	masterURL := ""
 	if j.OutputMasterKey != nil && *j.OutputMasterKey != "" && j.PlaybackReady {
-		u, err := app.PresignGet(r.Context(), *j.OutputMasterKey)
+		u, err := app.PresignGet(ctx, *j.OutputMasterKey)
 		if err == nil {
 			masterURL = u
 		}
 	}
 
-	httpx.Ok(w, "playback", types.PlaybackResp{
-		VideoID:             videoID,
+	return types.PlaybackResp{
+		VideoID:             v.ID,
 		JobID:               v.LatestJobID,
 		Status:              string(j.Status),
 		Progress:            j.Progress,
@@ -78,7 +91,29 @@ func (app *application) GetVideoPlayback(w http.ResponseWriter, r *http.Request)
 		AvailableRenditions: j.AvailableRenditions,
 		MasterKey:           j.OutputMasterKey,
 		MasterURL:           masterURL,
-	})
+		Renditions:          renditionResps(rends),
+	}, nil
+}
+
+func renditionResps(rs []store.Rendition) []types.RenditionResp {
+	out := make([]types.RenditionResp, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, types.RenditionResp{
+			Name:            r.Name,
+			VideoCodec:      r.VideoCodec,
+			AudioCodec:      r.AudioCodec,
+			Width:           r.Width,
+			Height:          r.Height,
+			TargetBitrate:   r.TargetBitrate,
+			MeasuredBitrate: r.MeasuredBitrate,
+			SegmentCount:    r.SegmentCount,
+			TotalBytes:      r.TotalBytes,
+			DurationSeconds: r.DurationSeconds,
+			PlaylistKey:     r.PlaylistKey,
+			Status:          string(r.Status),
+		})
+	}
+	return out
 }
 
 func (app *application) PresignVideoUpload(w http.ResponseWriter, r *http.Request) {
@@ -89,42 +124,18 @@ func (app *application) PresignVideoUpload(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	req.Title = strings.TrimSpace(req.Title)
-	req.Description = strings.TrimSpace(req.Description)
-	req.VideoFilename = strings.TrimSpace(req.VideoFilename)
-	req.ThumbFilename = strings.TrimSpace(req.ThumbFilename)
-
-	if req.VideoFilename == "" {
-		httpx.Fail(w, 400, "VALIDATION_ERROR", "videoFilename is required")
+	v, err := app.newUploadVideo(r.Context(), &req)
+	if err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
 		return
 	}
-	if req.VideoType == "" {
-		req.VideoType = "video/mp4"
-	}
-	if req.ThumbFilename == "" {
-		httpx.Fail(w, 400, "VALIDATION_ERROR", "thumbFilename is required")
+	if !app.reserveUpload(w, r, v.ID) {
 		return
 	}
-	if req.ThumbType == "" {
-		req.ThumbType = "image/jpeg"
-	}
-
-	videoID := uuid.NewString()
-
-	videoKey := app.config.s3.basePath + "inputs/" + videoID + "-" + utils.SafeFilename(req.VideoFilename)
-	thumbKey := app.config.s3.basePath + "thumbnails/" + videoID + "-" + utils.SafeFilename(req.ThumbFilename)
+	videoID, videoKey, thumbKey := v.ID, v.InputKey, v.ThumbnailKey
 
 	// Insert DB row first
-	if err := app.store.Video.Create(r.Context(), store.Video{
-		ID:           videoID,
-		Title:        req.Title,
-		Description:  req.Description,
-		Filename:     req.VideoFilename,
-		ContentType:  req.VideoType,
-		InputKey:     videoKey,
-		ThumbnailKey: thumbKey,
-		Status:       store.Uploaded,
-	}); err != nil {
+	if err := app.store.Video.Create(r.Context(), v); err != nil {
 		app.logger.Errorw("video create failed", "err", err)
 		httpx.Fail(w, 500, "DB_ERROR", err.Error())
 		return
@@ -154,58 +165,9 @@ func (app *application) PresignVideoUpload(w http.ResponseWriter, r *http.Reques
 	})
 }
 
-func (app *application) ListVideos(w http.ResponseWriter, r *http.Request) {
-	limit := utils.ParseInt(r.URL.Query().Get("limit"), 24)
-	offset := utils.ParseInt(r.URL.Query().Get("offset"), 0)
-	if limit <= 0 || limit > 100 {
-		limit = 24
-	}
-	items, total, err := app.store.Video.List(r.Context(), limit, offset)
-	if err != nil {
-		httpx.Fail(w, 500, "DB_ERROR", err.Error())
-		return
-	}
-
-	// attach thumbnailUrl via presigned GET (for the grid)
-	out := make([]map[string]any, 0, len(items))
-	for _, v := range items {
-		var thumbURL string
-		if v.ThumbnailKey != "" {
-			
-			u, err := app.PresignGet(r.Context(), v.ThumbnailKey)
-			if err == nil {
-				thumbURL = u
-			}
-		}
-
-		out = append(out, map[string]any{
-			"id":           v.ID,
-			"title":        v.Title,
-			"description":  v.Description,
-			"filename":     v.Filename,
-			"contentType":  v.ContentType,
-			"inputKey":     v.InputKey,
-			"thumbnailKey": v.ThumbnailKey,
-			"thumbnailUrl": thumbURL,
-			"latestJobId":  v.LatestJobID,
-			"status":       v.Status,
-			"errorMsg":     v.ErrorMsg,
-			"createdAt":    v.CreatedAt,
-			"updatedAt":    v.UpdatedAt,
-		})
-	}
-
-	httpx.Ok(w, "videos listed", map[string]any{
-		"items":  out,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
-	})
-}
-
 func (app *application) PresignPut(ctx context.Context, key, contentType string) (string, error) {
 	ps, err := app.s3Presign.PresignPutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(app.config.s3.bucket),
+		Bucket:      aws.String(app.tenantOf(ctx).Bucket),
 		Key:         aws.String(key),
 		ContentType: aws.String(contentType),
 	}, func(po *s3.PresignOptions) {
@@ -219,7 +181,7 @@ func (app *application) PresignPut(ctx context.Context, key, contentType string)
 
 func (app *application) PresignGet(ctx context.Context, key string) (string, error) {
 	ps, err := app.s3Presign.PresignGetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(app.config.s3.bucket),
+		Bucket: aws.String(app.tenantOf(ctx).Bucket),
 		Key:    aws.String(key),
 	}, func(po *s3.PresignOptions) {
 		po.Expires = app.config.s3.presignGETTTL
@@ -237,7 +199,7 @@ func (app *application) GetVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	v, err := app.store.Video.Get(r.Context(), id)
+	v, err := app.ownedVideo(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
 			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
@@ -247,6 +209,53 @@ func (app *application) GetVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// send it back in If-Match when updating the video
+	w.Header().Set("ETag", videoETag(v))
+	httpx.Ok(w, "video fetched", app.videoResp(r, v))
+}
+
+// newUploadVideo validates req, fills in its defaults and returns the
+// pending_upload video row for it with fresh S3 keys under the caller's
+// tenant prefix, owned by the caller.
+func (app *application) newUploadVideo(ctx context.Context, req *types.PresignVideoUploadReq) (store.Video, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	req.VideoFilename = strings.TrimSpace(req.VideoFilename)
+	req.ThumbFilename = strings.TrimSpace(req.ThumbFilename)
+
+	if req.VideoFilename == "" {
+		return store.Video{}, errors.New("videoFilename is required")
+	}
+	if req.VideoType == "" {
+		req.VideoType = "video/mp4"
+	}
+	if req.ThumbFilename == "" {
+		return store.Video{}, errors.New("thumbFilename is required")
+	}
+	if req.ThumbType == "" {
+		req.ThumbType = "image/jpeg"
+	}
+
+	videoID := uuid.NewString()
+	t := app.tenantOf(ctx)
+
+	return store.Video{
+		ID:           videoID,
+		TenantID:     t.ID,
+		Title:        req.Title,
+		Description:  req.Description,
+		Filename:     req.VideoFilename,
+		ContentType:  req.VideoType,
+		InputKey:     t.BasePath + "inputs/" + videoID + "-" + utils.SafeFilename(req.VideoFilename),
+		ThumbnailKey: t.BasePath + "thumbnails/" + videoID + "-" + utils.SafeFilename(req.ThumbFilename),
+		Status:       store.PendingUpload,
+		UploadState:  store.UploadPending,
+		Owner:        ownerOf(ctx),
+	}, nil
+}
+
+// videoResp renders a video with a presigned thumbnail URL.
+func (app *application) videoResp(r *http.Request, v store.Video) types.VideoResp {
 	thumbURL := ""
 	if v.ThumbnailKey != "" {
 		u, err := app.PresignGet(r.Context(), v.ThumbnailKey)
@@ -255,21 +264,26 @@ func (app *application) GetVideo(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	httpx.Ok(w, "video fetched", map[string]any{
-		"id":           v.ID,
-		"title":        v.Title,
-		"description":  v.Description,
-		"filename":     v.Filename,
-		"contentType":  v.ContentType,
-		"inputKey":     v.InputKey,
-		"thumbnailKey": v.ThumbnailKey,
-		"thumbnailUrl": thumbURL,
-		"latestJobId":  v.LatestJobID,
-		"status":       v.Status,
-		"errorMsg":     v.ErrorMsg,
-		"createdAt":    v.CreatedAt,
-		"updatedAt":    v.UpdatedAt,
-	})
+	return types.VideoResp{
+		ID:           v.ID,
+		Title:        v.Title,
+		Description:  v.Description,
+		Filename:     v.Filename,
+		ContentType:  v.ContentType,
+		InputKey:     v.InputKey,
+		ThumbnailKey: v.ThumbnailKey,
+		ThumbnailURL: thumbURL,
+		LatestJobID:  v.LatestJobID,
+		Status:       string(v.Status),
+		ErrorMsg:     v.ErrorMsg,
+		UploadState:  string(v.UploadState),
+		SizeBytes:    v.SizeBytes,
+		Duration:     v.DurationSeconds,
+		Owner:        v.Owner,
+		TenantID:     v.TenantID,
+		CreatedAt:    v.CreatedAt,
+		UpdatedAt:    v.UpdatedAt,
+	}
 }
 
 func (app *application) CreateVideoJob(w http.ResponseWriter, r *http.Request) {
@@ -280,7 +294,7 @@ func (app *application) CreateVideoJob(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ensure video exists (also gives us input_key)
-	v, err := app.store.Video.Get(r.Context(), videoID)
+	v, err := app.ownedVideo(r.Context(), videoID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
 			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
@@ -290,49 +304,136 @@ func (app *application) CreateVideoJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if v.Status == store.PendingUpload || v.UploadState != store.UploadCompleted {
+		httpx.Fail(w, 409, "UPLOAD_INCOMPLETE", "complete the upload with POST /v1/videos/{id}/complete first")
+		return
+	}
+
 	var req types.CreateVideoJobReq
 	_ = json.NewDecoder(r.Body).Decode(&req)
 	if req.Pipeline == "" {
-		req.Pipeline = "hls"
+		req.Pipeline = types.PipelineHLS
+	}
+	if err := types.ValidatePipeline(req.Pipeline); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	if err := req.Options.Validate(); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	policy := store.JobConflictPolicy(req.OnConflict)
+	if policy == "" {
+		policy = store.JobConflictReject
+	}
+	if !policy.Valid() {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "onConflict must be reject, supersede or queue")
+		return
+	}
+	if !app.allowQuota(w, r, quotaJob) {
+		return
 	}
 
-	
-	jobID := uuid.NewString()
-
-	if err := app.store.Job.Create(r.Context(), store.Job{
-		ID:       jobID,
-		VideoID:  videoID,
-		InputKey: v.InputKey,
-		Pipeline: req.Pipeline,
-		Status:   store.JobQueued,
-		Progress: 0,
-	}); err != nil {
+	jobID, superseded, err := app.enqueueJob(r.Context(), v, req, policy)
+	if err != nil {
+		if errors.Is(err, store.ErrJobActive) {
+			httpx.Fail(w, 409, "JOB_ACTIVE", "the video already has a queued or processing job; use onConflict supersede or queue")
+			return
+		}
+		var illegal *store.TransitionError
+		if errors.As(err, &illegal) {
+			httpx.Fail(w, 409, "ILLEGAL_TRANSITION", illegal.Error())
+			return
+		}
 		httpx.Fail(w, 500, "DB_ERROR", err.Error())
 		return
 	}
 
-	_ = app.store.Video.SetLatestJob(r.Context(), videoID, jobID)
+	supersededIDs := make([]string, 0, len(superseded))
+	for _, j := range superseded {
+		supersededIDs = append(supersededIDs, j.ID)
+	}
+	httpx.Created(w, "job created", types.CreateVideoJobResp{
+		VideoID:          v.ID,
+		JobID:            jobID,
+		Status:           string(store.JobQueued),
+		SupersededJobIDs: supersededIDs,
+	})
+}
 
-	
+// enqueueJob creates a queued job for v. The job row and its outbox entry
+// are committed together; the producer relay publishes the entry, so a job
+// is never left queued but unpublished. Jobs it supersedes get their
+// job.cancelled event in the same transaction.
+func (app *application) enqueueJob(ctx context.Context, v store.Video, req types.CreateVideoJobReq, policy store.JobConflictPolicy) (string, []store.Job, error) {
+	job, msgs, err := newJob(v, req)
+	if err != nil {
+		return "", nil, err
+	}
+	var superseded []store.Job
+	err = app.store.WithTx(ctx, func(tx store.Storage) error {
+		var err error
+		if superseded, err = tx.Job.Enqueue(ctx, job, policy, msgs...); err != nil {
+			return err
+		}
+		for _, j := range superseded {
+			ev := events.New(events.JobCancelled, events.SourceAPI, j.VideoID, j.ID, events.JobState{
+				Status:     string(store.JobCancelled),
+				Progress:   j.Progress,
+				Renditions: j.AvailableRenditions,
+				Error:      "superseded by job " + job.ID,
+			})
+			if err := events.Record(ctx, tx, ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return job.ID, superseded, nil
+}
 
-	resp, err := app.producer.Enqueue(r.Context(), &producerpb.EnqueueTranscodeJobRequest{
-		JobId:    jobID,
-		VideoId:  videoID,
+// newJob builds a queued job for v with its outbox entries: the transcode
+// message and the job.queued event.
+func newJob(v store.Video, req types.CreateVideoJobReq) (store.Job, []store.OutboxEntry, error) {
+	jobID := uuid.NewString()
+
+	payload, err := json.Marshal(types.TranscodeJobMessage{
+		JobID:    jobID,
+		TenantID: v.TenantID,
+		VideoID:  v.ID,
 		InputKey: v.InputKey,
 		Pipeline: req.Pipeline,
+		Options:  req.Options,
 	})
 	if err != nil {
-		httpx.Fail(w, 502, "PRODUCER_UNAVAILABLE", err.Error())
-		return
+		return store.Job{}, nil, err
 	}
-	if !resp.GetAccepted() {
-		httpx.Fail(w, 502, "PRODUCER_REJECTED", resp.GetMessage())
-		return
+
+	opts := req.Options.WithDefaults()
+	queued, err := events.New(events.JobQueued, events.SourceAPI, v.ID, jobID, events.JobState{
+		Status:  string(store.JobQueued),
+		Options: &opts,
+	}).Outbox()
+	if err != nil {
+		return store.Job{}, nil, err
 	}
 
-	httpx.Created(w, "job created", map[string]any{
-		"videoId": videoID,
-		"jobId":   jobID,
-		"status":  "queued",
-	})
+	job := store.Job{
+		ID:       jobID,
+		TenantID: v.TenantID,
+		VideoID:  v.ID,
+		InputKey: v.InputKey,
+		Pipeline: req.Pipeline,
+		Options:  req.Options,
+		Status:   store.JobQueued,
+		Progress: 0,
+	}
+	return job, []store.OutboxEntry{{
+		Kind:    store.OutboxTranscodeJob,
+		Key:     jobID,
+		Payload: payload,
+	}, queued}, nil
 }
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"video-encoding/shared/auth"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+	"video-encoding/shared/utils"
+)
+
+// ListVideos returns one page of videos.
+//
+//	GET /v1/videos?status=ready,failed&q=cats&createdFrom=...&createdTo=...
+//	    &minDuration=10&maxDuration=600&sort=-created_at&limit=24&cursor=...
+//
+// Pages are keyset-paginated: pass nextCursor back as cursor with the same
+// filters and sort. The total is only counted with includeTotal=true.
+func (app *application) ListVideos(w http.ResponseWriter, r *http.Request) {
+	f, err := parseVideoFilter(r.URL.Query())
+	if err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	// admins see every video and may filter by owner, others only their own
+	if p := auth.FromContext(r.Context()); !p.Admin {
+		f.Owner = &p.Subject
+	} else if o := strings.TrimSpace(r.URL.Query().Get("owner")); o != "" {
+		f.Owner = &o
+	}
+
+	items, next, err := app.store.Video.List(r.Context(), f)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	out := types.VideoListResp{
+		Items: make([]types.VideoResp, 0, len(items)),
+		Limit: f.Limit,
+	}
+	for _, v := range items {
+		out.Items = append(out.Items, app.videoResp(r, v))
+	}
+	if next != nil {
+		out.NextCursor = encodeVideoCursor(*next)
+		out.HasMore = true
+	}
+
+	if r.URL.Query().Get("includeTotal") == "true" {
+		total, err := app.store.Video.Count(r.Context(), f)
+		if err != nil {
+			httpx.Fail(w, 500, "DB_ERROR", err.Error())
+			return
+		}
+		out.Total = &total
+	}
+
+	httpx.Ok(w, "videos listed", out)
+}
+
+func parseVideoFilter(q url.Values) (store.VideoFilter, error) {
+	f := store.VideoFilter{
+		Query: strings.TrimSpace(q.Get("q")),
+		Sort:  store.VideoSort(q.Get("sort")),
+		Limit: utils.ParseInt(q.Get("limit"), 24),
+	}
+	if f.Limit <= 0 || f.Limit > 100 {
+		f.Limit = 24
+	}
+	if f.Sort == "" {
+		f.Sort = store.SortNewest
+	}
+	if !f.Sort.Valid() {
+		return f, fmt.Errorf("sort must be one of created_at, -created_at, title, -title, duration, -duration")
+	}
+
+	if s := q.Get("status"); s != "" {
+		for _, part := range strings.Split(s, ",") {
+			st := store.Status(strings.TrimSpace(part))
+			switch st {
+			case store.PendingUpload, store.Uploaded, store.Processing, store.Ready, store.Failed:
+				f.Statuses = append(f.Statuses, st)
+			default:
+				return f, fmt.Errorf("unknown status %q", part)
+			}
+		}
+	}
+
+	var err error
+	if f.CreatedFrom, err = parseTimeParam(q, "createdFrom"); err != nil {
+		return f, err
+	}
+	if f.CreatedTo, err = parseTimeParam(q, "createdTo"); err != nil {
+		return f, err
+	}
+	if f.MinDuration, err = parseFloatParam(q, "minDuration"); err != nil {
+		return f, err
+	}
+	if f.MaxDuration, err = parseFloatParam(q, "maxDuration"); err != nil {
+		return f, err
+	}
+
+	if c := q.Get("cursor"); c != "" {
+		cur, err := decodeVideoCursor(c)
+		if err != nil || cur.Sort != f.Sort {
+			return f, fmt.Errorf("cursor is invalid or was made for another sort")
+		}
+		f.After = &cur
+	}
+	return f, nil
+}
+
+func parseTimeParam(q url.Values, name string) (*time.Time, error) {
+	s := q.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+func parseFloatParam(q url.Values, name string) (*float64, error) {
+	s := q.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number of seconds", name)
+	}
+	return &n, nil
+}
+
+// Cursors are opaque to clients: base64url JSON of the last row's sort key.
+func encodeVideoCursor(c store.VideoCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeVideoCursor(s string) (store.VideoCursor, error) {
+	var c store.VideoCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+	"video-encoding/shared/utils"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+const (
+	maxTitleLen       = 200
+	maxDescriptionLen = 5000
+)
+
+var thumbTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// UpdateVideo changes a video's metadata. The client must send the ETag
+// from GET /v1/videos/{id} in If-Match (or updatedAt in the body); if the
+// video changed since, it gets 412 and the current state instead.
+func (app *application) UpdateVideo(w http.ResponseWriter, r *http.Request) {
+	videoID := chi.URLParam(r, "id")
+	if strings.TrimSpace(videoID) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return
+	}
+
+	var req types.UpdateVideoReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+
+	ifUpdatedAt, err := precondition(r, req.UpdatedAt)
+	if err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	if ifUpdatedAt == nil {
+		httpx.Fail(w, 428, "PRECONDITION_REQUIRED", "send If-Match with the video's ETag or updatedAt in the body")
+		return
+	}
+
+	var patch store.VideoPatch
+	if req.Title != nil {
+		t := strings.TrimSpace(*req.Title)
+		if utf8.RuneCountInString(t) > maxTitleLen {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", fmt.Sprintf("title must be at most %d characters", maxTitleLen))
+			return
+		}
+		patch.Title = &t
+	}
+	if req.Description != nil {
+		d := strings.TrimSpace(*req.Description)
+		if utf8.RuneCountInString(d) > maxDescriptionLen {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", fmt.Sprintf("description must be at most %d characters", maxDescriptionLen))
+			return
+		}
+		patch.Description = &d
+	}
+
+	var thumbKey string
+	if req.ThumbFilename != nil {
+		name := strings.TrimSpace(*req.ThumbFilename)
+		if name == "" {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", "thumbFilename must not be empty")
+			return
+		}
+		if req.ThumbType == "" {
+			req.ThumbType = "image/jpeg"
+		}
+		if !thumbTypes[req.ThumbType] {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", "thumbType must be image/jpeg, image/png or image/webp")
+			return
+		}
+		// a fresh key, so caches and the old object never serve stale bytes;
+		// the old thumbnail stays under the video's prefix until it is purged
+		thumbKey = app.tenantOf(r.Context()).BasePath + "thumbnails/" + videoID + "-" + uuid.NewString()[:8] + "-" + utils.SafeFilename(name)
+		patch.ThumbnailKey = &thumbKey
+	}
+
+	if patch == (store.VideoPatch{}) {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "nothing to update")
+		return
+	}
+
+	// the ETag is no authorization: other owners' videos don't exist here,
+	// whether or not the precondition would hold
+	if _, err := app.ownedVideo(r.Context(), videoID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	v, err := app.store.Video.Update(r.Context(), videoID, patch, *ifUpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			httpx.Fail(w, 404, "NOT_FOUND", "video not found")
+		case errors.Is(err, store.ErrConflict):
+			app.videoConflict(w, r, videoID)
+		default:
+			httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		}
+		return
+	}
+
+	resp := types.UpdateVideoResp{Video: app.videoResp(r, v)}
+	if thumbKey != "" {
+		u, err := app.PresignPut(r.Context(), thumbKey, req.ThumbType)
+		if err != nil {
+			app.logger.Errorw("presign thumb put failed", "err", err)
+			httpx.Fail(w, 500, "PRESIGN_FAILED", err.Error())
+			return
+		}
+		resp.ThumbKey = thumbKey
+		resp.ThumbPutURL = u
+	}
+
+	w.Header().Set("ETag", videoETag(v))
+	httpx.Ok(w, "video updated", resp)
+}
+
+// videoConflict answers 412 with the current video so the client can
+// merge and retry.
+func (app *application) videoConflict(w http.ResponseWriter, r *http.Request, videoID string) {
+	v, err := app.ownedVideo(r.Context(), videoID)
+	if err != nil {
+		httpx.Fail(w, 412, "PRECONDITION_FAILED", "video was modified, reload it and retry")
+		return
+	}
+	w.Header().Set("ETag", videoETag(v))
+	httpx.JSON(w, http.StatusPreconditionFailed, httpx.APIResponse{
+		Success: false,
+		Data:    app.videoResp(r, v),
+		Error:   &httpx.APIError{Code: "PRECONDITION_FAILED", Details: "video was modified, reload it and retry"},
+	})
+}
+
+// videoETag is derived from updated_at (microsecond precision, as stored).
+func videoETag(v store.Video) string {
+	return `"` + strconv.FormatInt(v.UpdatedAt.UnixMicro(), 10) + `"`
+}
+
+// precondition reads If-Match, falling back to the body's updatedAt.
+func precondition(r *http.Request, bodyUpdatedAt *time.Time) (*time.Time, error) {
+	if h := strings.TrimSpace(r.Header.Get("If-Match")); h != "" {
+		tag := strings.Trim(strings.TrimPrefix(h, "W/"), `"`)
+		us, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil {
+			return nil, errors.New("If-Match must be an ETag from GET /v1/videos/{id}")
+		}
+		t := time.UnixMicro(us)
+		return &t, nil
+	}
+	if bodyUpdatedAt != nil {
+		t := bodyUpdatedAt.Truncate(time.Microsecond)
+		return &t, nil
+	}
+	return nil, nil
+}
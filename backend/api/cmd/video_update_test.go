@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"video-encoding/shared/auth"
+	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
+
+	"go.uber.org/zap"
+)
+
+// fakeVideos keeps videos in memory. Methods the tests don't reach panic
+// on the nil embedded store.
+type fakeVideos struct {
+	*store.VideoStore
+	videos  map[string]store.Video
+	updates int
+}
+
+func (f *fakeVideos) Get(_ context.Context, id string) (store.Video, error) {
+	v, ok := f.videos[id]
+	if !ok {
+		return store.Video{}, store.ErrNotFound
+	}
+	return v, nil
+}
+
+func (f *fakeVideos) Update(_ context.Context, id string, p store.VideoPatch, ifUpdatedAt time.Time) (store.Video, error) {
+	v, ok := f.videos[id]
+	if !ok {
+		return store.Video{}, store.ErrNotFound
+	}
+	if !v.UpdatedAt.Equal(ifUpdatedAt) {
+		return store.Video{}, store.ErrConflict
+	}
+	if p.Title != nil {
+		v.Title = *p.Title
+	}
+	if p.Description != nil {
+		v.Description = *p.Description
+	}
+	v.UpdatedAt = v.UpdatedAt.Add(time.Second)
+	f.videos[id] = v
+	f.updates++
+	return v, nil
+}
+
+// TestVideoOwnership checks that callers only reach their own videos, and
+// that others' videos look the same as missing ones whatever the request.
+func TestVideoOwnership(t *testing.T) {
+	keys, err := auth.ParseAPIKeys("alice:alice-key,bob:bob-key,ops:ops-key:admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := tenant.Parse("", tenant.Tenant{Bucket: "videos", BasePath: "reels/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updatedAt := time.UnixMicro(1_700_000_000_000_000)
+	owner := "key:alice"
+	newApp := func() (*application, *fakeVideos) {
+		videos := &fakeVideos{videos: map[string]store.Video{
+			"v1": {ID: "v1", Title: "mine", Owner: &owner, Status: store.Ready, UpdatedAt: updatedAt},
+		}}
+		app := &application{
+			config:  config{auth: authConfig{enabled: true, authn: &auth.Authenticator{Keys: keys}}},
+			store:   store.Storage{Video: videos},
+			logger:  zap.NewNop().Sugar(),
+			tenants: tenants,
+		}
+		return app, videos
+	}
+
+	etag := videoETag(store.Video{UpdatedAt: updatedAt})
+	stale := videoETag(store.Video{UpdatedAt: updatedAt.Add(-time.Second)})
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		key     string
+		ifMatch string
+		status  int
+		updated bool
+	}{
+		{"owner reads", "GET", "/v1/videos/v1", "alice-key", "", 200, false},
+		{"other reads", "GET", "/v1/videos/v1", "bob-key", "", 404, false},
+		{"admin reads", "GET", "/v1/videos/v1", "ops-key", "", 200, false},
+		{"no credentials", "GET", "/v1/videos/v1", "", "", 401, false},
+
+		{"owner updates", "PATCH", "/v1/videos/v1", "alice-key", etag, 200, true},
+		{"owner with stale etag", "PATCH", "/v1/videos/v1", "alice-key", stale, 412, false},
+		{"admin updates", "PATCH", "/v1/videos/v1", "ops-key", etag, 200, true},
+		{"other updates", "PATCH", "/v1/videos/v1", "bob-key", etag, 404, false},
+		// 412 would tell bob the video exists
+		{"other with stale etag", "PATCH", "/v1/videos/v1", "bob-key", stale, 404, false},
+		{"other on a missing video", "PATCH", "/v1/videos/v2", "bob-key", etag, 404, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, videos := newApp()
+
+			var body *strings.Reader
+			if tt.method == "PATCH" {
+				body = strings.NewReader(`{"title":"renamed"}`)
+			} else {
+				body = strings.NewReader("")
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			app.mount().ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if got := videos.updates > 0; got != tt.updated {
+				t.Errorf("updated = %v, want %v", got, tt.updated)
+			}
+			if !tt.updated && videos.videos["v1"].Title != "mine" {
+				t.Errorf("title changed to %q", videos.videos["v1"].Title)
+			}
+			if tt.status == http.StatusNotFound && strings.Contains(rec.Body.String(), "mine") {
+				t.Errorf("404 leaks the video: %s", rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"video-encoding/shared/netguard"
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+	"video-encoding/shared/utils"
+	"video-encoding/shared/webhook"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+func (app *application) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if err := validateWebhookURL(req.URL, app.config.webhooks.allowPrivate); err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+		return
+	}
+
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			httpx.Fail(w, 500, "SECRET_FAILED", err.Error())
+			return
+		}
+	} else if len(secret) < 16 {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "secret must be at least 16 characters")
+		return
+	}
+
+	sub := store.WebhookSubscription{
+		ID:          uuid.NewString(),
+		URL:         req.URL,
+		Secret:      secret,
+		Description: strings.TrimSpace(req.Description),
+		Events:      events,
+		Active:      true,
+	}
+	if err := app.store.Webhook.CreateSubscription(r.Context(), sub); err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	created, err := app.store.Webhook.GetSubscription(r.Context(), sub.ID)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	// the secret is shown once; receivers need it to verify signatures
+	resp := webhookResp(created)
+	resp.Secret = created.Secret
+	httpx.Created(w, "webhook created", resp)
+}
+
+func (app *application) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := app.store.Webhook.ListSubscriptions(r.Context())
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	out := make([]types.WebhookResp, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, webhookResp(s))
+	}
+	httpx.Ok(w, "webhooks listed", out)
+}
+
+func (app *application) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	sub, ok := app.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+	httpx.Ok(w, "webhook fetched", webhookResp(sub))
+}
+
+func (app *application) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	sub, ok := app.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.UpdateWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Fail(w, 400, "INVALID_JSON", err.Error())
+		return
+	}
+
+	if req.URL != nil {
+		u := strings.TrimSpace(*req.URL)
+		if err := validateWebhookURL(u, app.config.webhooks.allowPrivate); err != nil {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		sub.URL = u
+	}
+	if req.Description != nil {
+		sub.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Events != nil {
+		events, err := normalizeEvents(*req.Events)
+		if err != nil {
+			httpx.Fail(w, 400, "VALIDATION_ERROR", err.Error())
+			return
+		}
+		sub.Events = events
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	if err := app.store.Webhook.UpdateSubscription(r.Context(), sub); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "webhook not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	updated, err := app.store.Webhook.GetSubscription(r.Context(), sub.ID)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+	httpx.Ok(w, "webhook updated", webhookResp(updated))
+}
+
+func (app *application) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := app.store.Webhook.DeleteSubscription(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "webhook not found")
+			return
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+	httpx.Ok(w, "webhook deleted", types.DeleteWebhookResp{ID: id})
+}
+
+// ListWebhookDeliveries returns the latest deliveries of a subscription
+// together with every attempt made for each of them.
+func (app *application) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	sub, ok := app.loadWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	limit := utils.ParseInt(r.URL.Query().Get("limit"), 20)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	deliveries, err := app.store.Webhook.ListDeliveries(r.Context(), sub.ID, limit)
+	if err != nil {
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return
+	}
+
+	out := make([]types.WebhookDeliveryResp, 0, len(deliveries))
+	for _, d := range deliveries {
+		attempts, err := app.store.Webhook.ListAttempts(r.Context(), d.ID)
+		if err != nil {
+			httpx.Fail(w, 500, "DB_ERROR", err.Error())
+			return
+		}
+
+		resp := types.WebhookDeliveryResp{
+			ID:             d.ID,
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			AttemptLog:     make([]types.WebhookAttemptResp, 0, len(attempts)),
+		}
+		if d.Status == store.DeliveryPending {
+			next := d.NextAttemptAt
+			resp.NextAttemptAt = &next
+		}
+		for _, a := range attempts {
+			resp.AttemptLog = append(resp.AttemptLog, types.WebhookAttemptResp{
+				Attempt:    a.Attempt,
+				StatusCode: a.StatusCode,
+				Error:      a.Error,
+				DurationMs: a.Duration.Milliseconds(),
+				CreatedAt:  a.CreatedAt,
+			})
+		}
+		out = append(out, resp)
+	}
+
+	httpx.Ok(w, "deliveries listed", out)
+}
+
+func (app *application) loadWebhook(w http.ResponseWriter, r *http.Request) (store.WebhookSubscription, bool) {
+	id := chi.URLParam(r, "id")
+	if strings.TrimSpace(id) == "" {
+		httpx.Fail(w, 400, "VALIDATION_ERROR", "id is required")
+		return store.WebhookSubscription{}, false
+	}
+
+	sub, err := app.store.Webhook.GetSubscription(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Fail(w, 404, "NOT_FOUND", "webhook not found")
+			return store.WebhookSubscription{}, false
+		}
+		httpx.Fail(w, 500, "DB_ERROR", err.Error())
+		return store.WebhookSubscription{}, false
+	}
+	return sub, true
+}
+
+func webhookResp(s store.WebhookSubscription) types.WebhookResp {
+	events := s.Events
+	if events == nil {
+		events = []string{}
+	}
+	return types.WebhookResp{
+		ID:          s.ID,
+		URL:         s.URL,
+		Description: s.Description,
+		Events:      events,
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+// validateWebhookURL rejects URLs naming a private address outright; names
+// resolving to one are refused by the dispatcher on every delivery.
+func validateWebhookURL(raw string, allowPrivate bool) error {
+	if raw == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	if allowPrivate {
+		return nil
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && netguard.IsPrivate(ip)) || strings.EqualFold(host, "localhost") {
+		return errors.New("url must not point to a private address")
+	}
+	return nil
+}
+
+func normalizeEvents(in []string) ([]string, error) {
+	out := make([]string, 0, len(in))
+	seen := make(map[string]bool, len(in))
+	for _, e := range in {
+		e = strings.TrimSpace(e)
+		if !webhook.KnownEvent(e) {
+			return nil, fmt.Errorf("unknown event %q, expected one of %s", e, strings.Join(webhook.EventTypes, ", "))
+		}
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
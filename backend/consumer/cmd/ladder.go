@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"video-encoding/shared/types"
+)
+
+// rendition is one rung of an HLS bitrate ladder. Bitrates are in kbps.
+type rendition struct {
+	Name    string
+	Width   int
+	Height  int
+	Bitrate int
+	MaxRate int
+	BufSize int
+}
+
+var ladders = map[string][]rendition{
+	types.LadderStandard: {
+		{Name: "480p", Width: 854, Height: 480, Bitrate: 800, MaxRate: 900, BufSize: 1200},
+		{Name: "720p", Width: 1280, Height: 720, Bitrate: 2000, MaxRate: 2200, BufSize: 3000},
+		{Name: "1080p", Width: 1920, Height: 1080, Bitrate: 4500, MaxRate: 5000, BufSize: 6500},
+	},
+	types.LadderMobile: {
+		{Name: "360p", Width: 640, Height: 360, Bitrate: 500, MaxRate: 600, BufSize: 800},
+		{Name: "480p", Width: 854, Height: 480, Bitrate: 800, MaxRate: 900, BufSize: 1200},
+		{Name: "720p", Width: 1280, Height: 720, Bitrate: 2000, MaxRate: 2200, BufSize: 3000},
+	},
+	types.LadderHD: {
+		{Name: "720p", Width: 1280, Height: 720, Bitrate: 2000, MaxRate: 2200, BufSize: 3000},
+		{Name: "1080p", Width: 1920, Height: 1080, Bitrate: 4500, MaxRate: 5000, BufSize: 6500},
+	},
+}
+
+func ladderFor(opts types.JobOptions) []rendition {
+	if l, ok := ladders[opts.LadderProfile]; ok {
+		return l
+	}
+	return ladders[types.LadderStandard]
+}
+
+func renditionNames(l []rendition) []string {
+	out := make([]string, 0, len(l))
+	for _, r := range l {
+		out = append(out, r.Name)
+	}
+	return out
+}
+
+// hlsArgs builds the ffmpeg command line for one input and a ladder.
+// Variant playlists and segments are named after the rendition
+// (480p.m3u8, 480p_000.ts, ...) next to master.m3u8.
+func hlsArgs(inputPath, outDir string, l []rendition, opts types.JobOptions) []string {
+	opts = opts.WithDefaults()
+	withAudio := opts.AudioCodec != types.AudioCodecNone
+
+	var filter strings.Builder
+	filter.WriteString("[0:v]split=" + strconv.Itoa(len(l)))
+	for i := range l {
+		fmt.Fprintf(&filter, "[v%d]", i)
+	}
+	for i, r := range l {
+		fmt.Fprintf(&filter, ";[v%d]scale=w=%d:h=%d:force_original_aspect_ratio=decrease,"+
+			"scale=trunc(iw/2)*2:trunc(ih/2)*2[v%dout]", i, r.Width, r.Height, i)
+	}
+
+	args := []string{
+		"-y",
+		"-i", inputPath,
+
+		"-filter_complex", filter.String(),
+	}
+
+	// map video+audio
+	for i := range l {
+		args = append(args, "-map", fmt.Sprintf("[v%dout]", i))
+		if withAudio {
+			args = append(args, "-map", "0:a?")
+		}
+	}
+
+	// video encode
+	encoder, profile := "libx264", "high"
+	if opts.VideoCodec == types.VideoCodecH265 {
+		encoder, profile = "libx265", "main"
+	}
+	for i, r := range l {
+		n := strconv.Itoa(i)
+		args = append(args,
+			"-c:v:"+n, encoder, "-profile:v:"+n, profile, "-pix_fmt", "yuv420p",
+			"-b:v:"+n, fmt.Sprintf("%dk", r.Bitrate),
+			"-maxrate:v:"+n, fmt.Sprintf("%dk", r.MaxRate),
+			"-bufsize:v:"+n, fmt.Sprintf("%dk", r.BufSize),
+		)
+	}
+	if opts.VideoCodec == types.VideoCodecH265 {
+		// Apple players only accept HEVC tagged as hvc1
+		args = append(args, "-tag:v", "hvc1")
+	}
+
+	// audio
+	switch opts.AudioCodec {
+	case types.AudioCodecAAC:
+		args = append(args, "-c:a", "aac", "-b:a", "128k", "-ac", "2")
+	case types.AudioCodecAC3:
+		args = append(args, "-c:a", "ac3", "-b:a", "192k")
+	}
+
+	// GOP alignment for HLS (30fps * segment length)
+	gop := strconv.Itoa(30 * opts.SegmentSeconds)
+	args = append(args,
+		"-g", gop,
+		"-keyint_min", gop,
+		"-sc_threshold", "0",
+	)
+
+	streams := make([]string, 0, len(l))
+	for i, r := range l {
+		if withAudio {
+			streams = append(streams, fmt.Sprintf("v:%d,a:%d,name:%s", i, i, r.Name))
+		} else {
+			streams = append(streams, fmt.Sprintf("v:%d,name:%s", i, r.Name))
+		}
+	}
+
+	// HLS output
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(opts.SegmentSeconds),
+		"-hls_playlist_type", "vod",
+		"-hls_flags", "independent_segments",
+		"-hls_segment_filename", filepath.Join(outDir, "%v_%03d.ts"),
+
+		"-master_pl_name", "master.m3u8",
+		"-var_stream_map", strings.Join(streams, " "),
+
+		filepath.Join(outDir, "%v.m3u8"),
+	)
+
+	return args
+}
@@ -2,10 +2,19 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 	"video-encoding/shared/db"
 	"video-encoding/shared/env"
+	"video-encoding/shared/queue"
+	"video-encoding/shared/queue/kafka"
 	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
 
 	s3_Config "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -13,18 +22,26 @@ import (
 )
 
 type config struct {
+	workerID string
 	broker  string
 	groupId string
 	topic   string
+	queue   queueConfig
 	db      dbConfig
 	s3      s3Config
 }
 
+type queueConfig struct {
+	backend      string
+	pollInterval time.Duration
+}
+
 type dbConfig struct {
 	addr         string
 	maxOpenConns int
 	maxIdleConns int
 	maxIdleTime  string
+	autoMigrate  bool
 }
 
 type application struct {
@@ -44,9 +61,14 @@ func main() {
 	log := zap.Must(zap.NewProduction()).Sugar()
 	defer log.Sync()
 	cfg := config{
+		workerID: env.GetString("WORKER_ID", hostname()),
 		broker:  env.GetString("BROKER", "kafka:9092"),
 		groupId: env.GetString("GROUPID", "consumer-group-1"),
-		topic:   env.GetString("TOPIC", "video.transcode.jobs"),
+		topic:   env.GetString("TOPIC", queue.DefaultTopic),
+		queue: queueConfig{
+			backend:      env.GetString("QUEUE_BACKEND", queue.BackendKafka),
+			pollInterval: env.GetDuration("QUEUE_POLL_INTERVAL", 250*time.Millisecond),
+		},
 		s3: s3Config{
 			region:        env.GetString("S3_REGION", "ap-south-1"),
 			bucket:        env.GetString("S3_BUCKET", "your-bucket-name"),
@@ -60,6 +82,7 @@ func main() {
 			maxOpenConns: env.GetInt("DB_MAX_OPEN_CONNS", 30),
 			maxIdleConns: env.GetInt("DB_MAX_IDLE_CONNS", 30),
 			maxIdleTime:  env.GetString("DB_MAX_IDLE_TIME", "15m"),
+			autoMigrate:  env.GetBool("DB_AUTO_MIGRATE", false),
 		},
 	}
 
@@ -69,22 +92,73 @@ func main() {
 		log.Fatalf("Database connection failed: %v", err)
 	}
 
+	if cfg.db.autoMigrate {
+		if err := migrateUp(context.Background(), db, log); err != nil {
+			log.Fatalw("migrations failed", "err", err)
+		}
+	}
+
 	awsCfg, err := s3_Config.LoadDefaultConfig(context.Background(), s3_Config.WithRegion(cfg.s3.region))
 	if err != nil {
 		log.Fatalw("aws config error", "err", err)
 	}
 	s3Client := s3.NewFromConfig(awsCfg)
 	store := store.NewStorage(db)
-	w := NewWorker(log, store, cfg.broker,
-		cfg.groupId,
-		cfg.topic,
+
+	q, err := openQueue(cfg, db)
+	if err != nil {
+		log.Fatalw("job queue init failed", "err", err)
+	}
+
+	// must match the API's, which picks the keys jobs read and write
+	tenants, err := tenant.Parse(env.GetString("TENANTS", ""), tenant.Tenant{
+		Bucket:   cfg.s3.bucket,
+		BasePath: cfg.s3.basePath,
+	})
+	if err != nil {
+		log.Fatalw("tenant config error", "err", err)
+	}
+
+	w := NewWorker(cfg.workerID, log, store, q,
 		s3Client,
-		cfg.s3.bucket,
-		cfg.s3.basePath)
+		tenants)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	ctx := context.Background()
-	log.Infow("consumer starting", "brokers", cfg.broker, "topic", cfg.topic, "group", cfg.groupId)
-	if err := w.Run(ctx, 250*time.Millisecond); err != nil {
+	log.Infow("consumer starting", "backend", cfg.queue.backend, "brokers", cfg.broker, "topic", cfg.topic, "group", cfg.groupId)
+	if err := w.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
 		log.Fatalw("consumer stopped with error", "err", err)
 	}
 }
+
+func openQueue(cfg config, conn *sql.DB) (queue.JobQueue, error) {
+	switch cfg.queue.backend {
+	case queue.BackendKafka:
+		return kafka.NewQueue(kafka.Config{
+			Brokers:      cfg.broker,
+			GroupID:      cfg.groupId,
+			Topic:        cfg.topic,
+			PollInterval: cfg.queue.pollInterval,
+			// the worker doesn't poll while a job runs
+			MaxPollInterval: jobTimeout + 5*time.Minute,
+		}), nil
+	case queue.BackendMemory:
+		return nil, queue.ErrInProcess
+	case queue.BackendPostgres:
+		return queue.NewPostgresQueue(conn, queue.PostgresOptions{
+			Name:         cfg.topic,
+			PollInterval: cfg.queue.pollInterval,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown QUEUE_BACKEND %q", cfg.queue.backend)
+	}
+}
+
+func hostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "worker"
+	}
+	return h
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+)
+
+// renditionRecord describes a rung of the ladder before it is encoded.
+func renditionRecord(jobID, outputBase string, r rendition, opts types.JobOptions) store.Rendition {
+	opts = opts.WithDefaults()
+	return store.Rendition{
+		JobID:         jobID,
+		Name:          r.Name,
+		VideoCodec:    opts.VideoCodec,
+		AudioCodec:    opts.AudioCodec,
+		Width:         r.Width,
+		Height:        r.Height,
+		TargetBitrate: r.Bitrate,
+		PlaylistKey:   outputBase + r.Name + ".m3u8",
+		Status:        store.RenditionPending,
+	}
+}
+
+// measureRendition fills in the segment count, size, duration and real
+// bitrate of an encoded rendition from its variant playlist in outDir.
+func measureRendition(outDir string, rec *store.Rendition) error {
+	playlist := filepath.Join(outDir, rec.Name+".m3u8")
+
+	f, err := os.Open(playlist)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var segments int
+	var segmentBytes int64
+	var duration float64
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			// #EXTINF:4.000000,
+			v, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			if d, err := strconv.ParseFloat(v, 64); err == nil {
+				duration += d
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			fi, err := os.Stat(filepath.Join(outDir, line))
+			if err != nil {
+				return err
+			}
+			segments++
+			segmentBytes += fi.Size()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	total := segmentBytes
+	if fi, err := f.Stat(); err == nil {
+		total += fi.Size()
+	}
+
+	rec.SegmentCount = segments
+	rec.TotalBytes = total
+	rec.DurationSeconds = duration
+	if duration > 0 {
+		kbps := int(math.Round(float64(segmentBytes) * 8 / duration / 1000))
+		rec.MeasuredBitrate = &kbps
+	}
+	return nil
+}
+
+// videoDuration is the longest measured rendition; renditions of one input
+// only differ by a partial segment.
+func videoDuration(recs []store.Rendition) float64 {
+	var d float64
+	for _, r := range recs {
+		d = math.Max(d, r.DurationSeconds)
+	}
+	return d
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"video-encoding/shared/db"
+
+	"go.uber.org/zap"
+)
+
+func migrateUp(ctx context.Context, conn *sql.DB, log *zap.SugaredLogger) error {
+	applied, err := db.MigrateUp(ctx, conn)
+	for _, m := range applied {
+		log.Infow("migration applied", "version", m.Version, "name", m.Name)
+	}
+	return err
+}
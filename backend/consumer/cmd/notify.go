@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"video-encoding/shared/events"
+	"video-encoding/shared/store"
+	"video-encoding/shared/webhook"
+)
+
+// notify records a lifecycle event with record. It is best-effort and must
+// not hold up the pipeline, so it runs detached from the job context (which
+// may already be timed out when a failure is reported).
+func (w *Worker) notify(ctx context.Context, eventType string, msg jobRun, st events.JobState) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := w.record(ctx, w.store, eventType, msg, st); err != nil {
+		w.log.Warnw("job event not recorded", "event", eventType, "jobId", msg.JobID, "err", err)
+	}
+}
+
+// record writes a lifecycle event: on the job timeline, as a Kafka event
+// (through the outbox) and, for the types subscribers can ask for, as a
+// webhook. Pass a Storage from WithTx to write it with the change it
+// describes.
+func (w *Worker) record(ctx context.Context, s store.Storage, eventType string, msg jobRun, st events.JobState) error {
+	if err := s.Job.AddEvent(ctx, timelineEvent(eventType, msg, st, w.id)); err != nil {
+		return fmt.Errorf("timeline: %w", err)
+	}
+
+	ev := events.New(eventType, events.SourceWorker, msg.VideoID, msg.JobID, st)
+	if err := events.Record(ctx, s, ev); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+
+	if !webhook.KnownEvent(eventType) {
+		return nil
+	}
+	if err := webhook.Emit(ctx, s, eventType, webhook.JobData{
+		JobID:      msg.JobID,
+		VideoID:    msg.VideoID,
+		Status:     st.Status,
+		Progress:   st.Progress,
+		Renditions: st.Renditions,
+		MasterKey:  st.MasterKey,
+		Error:      st.Error,
+	}); err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+func timelineEvent(eventType string, msg jobRun, st events.JobState, worker string) store.JobEvent {
+	e := store.JobEvent{
+		JobID:    msg.JobID,
+		VideoID:  msg.VideoID,
+		Type:     eventType,
+		Status:   store.JobStatus(st.Status),
+		Progress: st.Progress,
+		Attempt:  msg.Attempt,
+		Worker:   worker,
+	}
+	if st.Error != "" {
+		e.Error = &st.Error
+	}
+
+	details := map[string]any{}
+	if st.Rendition != "" {
+		details["rendition"] = st.Rendition
+	}
+	if len(st.Renditions) > 0 {
+		details["renditions"] = st.Renditions
+	}
+	if st.MasterKey != "" {
+		details["masterKey"] = st.MasterKey
+	}
+	if len(details) > 0 {
+		e.Details = details
+	}
+	return e
+}
@@ -1,11 +1,9 @@
 package main
 
 import (
-	
 	"bytes"
 	"context"
-	"encoding/json"
-	
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -14,99 +12,146 @@ import (
 	"strings"
 	"time"
 
-	consumerkafka "video-encoding/consumer/internal"
+	"video-encoding/shared/events"
+	"video-encoding/shared/queue"
 	"video-encoding/shared/store"
+	"video-encoding/shared/tenant"
 	"video-encoding/shared/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	ckafka "github.com/confluentinc/confluent-kafka-go/kafka"
 	"go.uber.org/zap"
 )
 
+const cancelCheckEvery = 5 * time.Second
+
+// jobTimeout bounds one job so workers don't hang forever. The queue must
+// not hand the job to another worker before it is up.
+const jobTimeout = 30 * time.Minute
+
+var errJobCancelled = errors.New("job cancelled")
+
+// jobRun is a job message together with the queue delivery attempt.
+type jobRun struct {
+	types.TranscodeJobMessage
+	Attempt int
+}
+
 type Worker struct {
+	id    string // recorded on job events, e.g. the hostname
 	log   *zap.SugaredLogger
 	store store.Storage
-	co    *consumerkafka.Consumer
-	topic string
+	queue queue.JobQueue
 
-	s3       *s3.Client
-	s3Bucket string
-	s3Base   string // e.g. "reels/"
+	s3      *s3.Client
+	tenants *tenant.Registry // bucket and base path (e.g. "reels/") per tenant
 }
 
 func NewWorker(
+	id string,
 	log *zap.SugaredLogger,
 	st store.Storage,
-	brokers, groupID, topic string,
+	q queue.JobQueue,
 	s3Client *s3.Client,
-	s3Bucket string,
-	s3Base string,
+	tenants *tenant.Registry,
 ) *Worker {
-	co, err := consumerkafka.NewConsumer(brokers, groupID)
-	if err != nil {
-		log.Fatalw("kafka consumer init failed", "err", err)
-	}
-	if err := co.Subscribe(topic); err != nil {
-		log.Fatalw("kafka subscribe failed", "err", err)
-	}
-
 	return &Worker{
-		log:      log,
-		store:    st,
-		co:       co,
-		topic:    topic,
-		s3:       s3Client,
-		s3Bucket: s3Bucket,
-		s3Base:   s3Base,
+		id:      id,
+		log:     log,
+		store:   st,
+		queue:   q,
+		s3:      s3Client,
+		tenants: tenants,
 	}
 }
 
-func (w *Worker) Run(ctx context.Context, pollEvery time.Duration) error {
-	defer w.co.Close()
+func (w *Worker) Run(ctx context.Context) error {
+	defer w.queue.Close()
 
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		msgAny, err := w.co.Poll(int(pollEvery / time.Millisecond))
+		d, err := w.queue.Consume(ctx)
 		if err != nil {
-			// transient error is okay; continue
-			continue
-		}
-
-		m, ok := msgAny.(*ckafka.Message)
-		if !ok || m == nil || len(m.Value) == 0 {
-			continue
-		}
-
-		var job types.TranscodeJobMessage
-		if err := json.Unmarshal(m.Value, &job); err != nil {
-			w.log.Errorw("bad message json", "err", err, "payload", string(m.Value))
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, queue.ErrClosed) {
+				return err
+			}
+			if errors.Is(err, queue.ErrMalformed) {
+				w.log.Errorw("bad message", "err", err)
+				continue
+			}
+			// transient error is okay; back off a little and continue
+			w.log.Warnw("queue consume failed", "err", err)
+			time.Sleep(time.Second)
 			continue
 		}
 
+		job := d.Job
 		if strings.TrimSpace(job.JobID) == "" || strings.TrimSpace(job.VideoID) == "" || strings.TrimSpace(job.InputKey) == "" {
 			w.log.Errorw("invalid job message", "job", job)
+			_ = w.queue.Ack(ctx, d)
 			continue
 		}
 
-		// process each message with a bounded timeout so workers don't hang forever
-		jobCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
-		w.processOne(jobCtx, job)
+		jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+		w.processOne(jobCtx, jobRun{TranscodeJobMessage: job, Attempt: d.Attempt})
 		cancel()
+
+		// shutting down mid-job: hand the job back so another worker retries it
+		if ctx.Err() != nil {
+			_ = w.queue.Nack(context.Background(), d, true)
+			return ctx.Err()
+		}
+		if err := w.queue.Ack(ctx, d); err != nil {
+			w.log.Warnw("queue ack failed", "jobId", job.JobID, "err", err)
+		}
 	}
 }
 
-func (w *Worker) processOne(ctx context.Context, msg types.TranscodeJobMessage) {
-	log := w.log.With("jobId", msg.JobID, "videoId", msg.VideoID, "inputKey", msg.InputKey, "pipeline", msg.Pipeline)
+func (w *Worker) processOne(ctx context.Context, msg jobRun) {
+	log := w.log.With("jobId", msg.JobID, "videoId", msg.VideoID, "tenant", msg.TenantID, "inputKey", msg.InputKey, "pipeline", msg.Pipeline, "attempt", msg.Attempt)
 
-	// Mark job/video processing (best-effort; do not stop pipeline if this fails)
-	_ = w.store.Job.MarkProcessing(ctx, msg.JobID)
-	_ = w.store.Video.MarkProcessing(ctx, msg.VideoID)
+	if j, err := w.store.Job.Get(ctx, msg.JobID); errors.Is(err, store.ErrNotFound) {
+		log.Infow("job deleted before start, skipping")
+		return
+	} else if err == nil && j.Status == store.JobCancelled {
+		log.Infow("job cancelled before start, skipping")
+		return
+	}
+
+	ctx, stop := w.watchCancel(ctx, msg.JobID)
+	defer stop()
+
+	// Mark job processing; the video follows. Best-effort, except that a
+	// job which already ended is never run again.
+	var illegal *store.TransitionError
+	if err := w.store.Job.MarkProcessing(ctx, msg.JobID); errors.As(err, &illegal) {
+		log.Infow("job already ended, skipping", "status", illegal.From)
+		return
+	}
+	w.notify(ctx, events.JobStarted, msg, events.JobState{Status: string(store.JobProcessing)})
+
+	t, err := w.tenants.Get(msg.TenantID)
+	if err != nil {
+		w.fail(ctx, msg, err)
+		return
+	}
+
+	// S3 base: <tenant base>outputs/<video>/<job>/
+	outputBase := t.BasePath + "outputs/" + msg.VideoID + "/" + msg.JobID + "/"
+	masterKey := outputBase + "master.m3u8"
+
+	ladder := ladderFor(msg.Options)
+	renditions := renditionNames(ladder) // target qualities
+	records := make([]store.Rendition, 0, len(ladder))
+	for _, r := range ladder {
+		rec := renditionRecord(msg.JobID, outputBase, r, msg.Options)
+		if err := w.store.Rendition.Upsert(ctx, rec); err != nil {
+			log.Warnw("rendition not recorded", "rendition", r.Name, "err", err)
+		}
+		records = append(records, rec)
+	}
 
 	// Create working directory
 	workDir, err := os.MkdirTemp("", "transcode-"+msg.JobID+"-*")
@@ -119,11 +164,12 @@ func (w *Worker) processOne(ctx context.Context, msg types.TranscodeJobMessage)
 	inputPath := filepath.Join(workDir, "input.mp4")
 
 	// 1) Download input from S3
-	if err := w.downloadFromS3(ctx, msg.InputKey, inputPath); err != nil {
+	if err := w.downloadFromS3(ctx, t.Bucket, msg.InputKey, inputPath); err != nil {
 		w.fail(ctx, msg, fmt.Errorf("download input from s3: %w", err))
 		return
 	}
 	_ = w.store.Job.UpdateProgress(ctx, msg.JobID, 10, nil, nil, false)
+	w.notify(ctx, events.JobProgress, msg, events.JobState{Status: string(store.JobProcessing), Progress: 10})
 
 	// 2) Run ffmpeg → produce HLS outputs in local dir
 	outDir := filepath.Join(workDir, "hls")
@@ -132,51 +178,145 @@ func (w *Worker) processOne(ctx context.Context, msg types.TranscodeJobMessage)
 		return
 	}
 
-	renditions := []string{"480p", "720p", "1080p"} // target qualities
-	if err := w.transcodeToHLS(ctx, inputPath, outDir, log); err != nil {
+	if err := w.transcodeToHLS(ctx, inputPath, outDir, ladder, msg.Options, log); err != nil {
 		w.fail(ctx, msg, fmt.Errorf("ffmpeg transcode: %w", err))
 		return
 	}
-	_ = w.store.Job.UpdateProgress(ctx, msg.JobID, 70, []string{"480p"}, nil, false) // conservative midpoint update
+	_ = w.store.Job.UpdateProgress(ctx, msg.JobID, 70, renditions[:1], nil, false) // conservative midpoint update
+	w.notify(ctx, events.JobProgress, msg, events.JobState{Status: string(store.JobProcessing), Progress: 70, Renditions: renditions[:1]})
 
-	// 3) Upload HLS folder to S3
-	// S3 base: reels/outputs/<video>/<job>/
-	outputBase := w.s3Base + "outputs/" + msg.VideoID + "/" + msg.JobID + "/"
-	masterKey := outputBase + "master.m3u8"
+	for i := range records {
+		if err := measureRendition(outDir, &records[i]); err != nil {
+			log.Warnw("rendition not measured", "rendition", records[i].Name, "err", err)
+		}
+	}
 
-	if err := w.uploadDirToS3(ctx, outDir, outputBase); err != nil {
+	// 3) Upload HLS folder to S3
+	if err := w.uploadDirToS3(ctx, t.Bucket, outDir, outputBase); err != nil {
 		w.fail(ctx, msg, fmt.Errorf("upload outputs to s3: %w", err))
 		return
 	}
+	for i, name := range renditions {
+		records[i].Status = store.RenditionReady
+		if err := w.store.Rendition.Upsert(ctx, records[i]); err != nil {
+			log.Warnw("rendition not recorded", "rendition", name, "err", err)
+		}
+		w.notify(ctx, events.JobRenditionReady, msg, events.JobState{
+			Status:     string(store.JobProcessing),
+			Progress:   70,
+			Rendition:  name,
+			Renditions: renditions[:i+1],
+		})
+	}
 
-	// 4) Mark playable + completed
-	if err := w.store.Job.UpdateProgress(ctx, msg.JobID, 100, renditions, &masterKey, true); err != nil {
-		// even if this fails, we still try to mark failed so the system isn't stuck
-		w.fail(ctx, msg, fmt.Errorf("db update progress final: %w", err))
+	// 4) Mark playable + completed, with the video and the event, in one
+	// transaction
+	err = w.store.WithTx(ctx, func(tx store.Storage) error {
+		if err := tx.Job.UpdateProgress(ctx, msg.JobID, 100, renditions, &masterKey, true); err != nil {
+			return err
+		}
+		if err := tx.Job.MarkCompleted(ctx, msg.JobID); err != nil {
+			return err
+		}
+		if d := videoDuration(records); d > 0 {
+			if err := tx.Video.SetDuration(ctx, msg.VideoID, d); err != nil {
+				return err
+			}
+		}
+		return w.record(ctx, tx, events.JobCompleted, msg, events.JobState{
+			Status:     string(store.JobCompleted),
+			Progress:   100,
+			Renditions: renditions,
+			MasterKey:  masterKey,
+		})
+	})
+	switch {
+	case errors.Is(err, store.ErrConflict):
+		log.Infow("job ended meanwhile, not completed")
+		return
+	case err != nil:
+		// still try to mark it failed so the system isn't stuck
+		w.fail(ctx, msg, fmt.Errorf("record completion: %w", err))
 		return
 	}
 
-	_ = w.store.Job.MarkCompleted(ctx, msg.JobID)
-	_ = w.store.Video.MarkReady(ctx, msg.VideoID)
-
 	log.Infow("job completed", "masterKey", masterKey)
 }
 
-func (w *Worker) fail(ctx context.Context, msg types.TranscodeJobMessage, err error) {
+func (w *Worker) fail(ctx context.Context, msg jobRun, err error) {
+	cancelled := errors.Is(context.Cause(ctx), errJobCancelled)
+	// the worker is shutting down (a timeout is DeadlineExceeded instead)
+	interrupted := !cancelled && errors.Is(ctx.Err(), context.Canceled)
+	// detached: ctx may be the reason we are failing
+	ctx = context.WithoutCancel(ctx)
+
+	if cancelled {
+		_ = w.store.Rendition.FailPending(ctx, msg.JobID)
+		w.log.Infow("job cancelled", "jobId", msg.JobID, "videoId", msg.VideoID)
+		return
+	}
+	if interrupted {
+		// failed is final; leave the job processing so the worker that
+		// gets the requeued message can run it again
+		w.log.Infow("job interrupted by shutdown, handing it back", "jobId", msg.JobID, "videoId", msg.VideoID, "err", err)
+		return
+	}
+
 	w.log.Errorw("job failed", "jobId", msg.JobID, "videoId", msg.VideoID, "err", err)
 
 	// Store failure in DB (best effort)
-	_ = w.store.Job.MarkFailed(ctx, msg.JobID, err.Error())
-	_ = w.store.Video.MarkFailed(ctx, msg.VideoID, err.Error())
+	terr := w.store.WithTx(ctx, func(tx store.Storage) error {
+		if err := tx.Rendition.FailPending(ctx, msg.JobID); err != nil {
+			return err
+		}
+		if err := tx.Job.MarkFailed(ctx, msg.JobID, err.Error()); err != nil {
+			return err
+		}
+		return w.record(ctx, tx, events.JobFailed, msg, events.JobState{Status: string(store.JobFailed), Error: err.Error()})
+	})
+	if terr == nil {
+		return
+	}
+	_ = w.store.Rendition.FailPending(ctx, msg.JobID)
+	if errors.Is(terr, store.ErrConflict) {
+		w.log.Infow("job ended meanwhile, not marked failed", "jobId", msg.JobID)
+		return
+	}
+	w.log.Warnw("job failure not recorded", "jobId", msg.JobID, "err", terr)
+}
+
+// watchCancel returns a context that is cancelled with errJobCancelled as
+// soon as the job is cancelled or deleted in the DB, which also kills ffmpeg.
+func (w *Worker) watchCancel(ctx context.Context, jobID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	go func() {
+		t := time.NewTicker(cancelCheckEvery)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				j, err := w.store.Job.Get(ctx, jobID)
+				if errors.Is(err, store.ErrNotFound) || (err == nil && j.Status == store.JobCancelled) {
+					cancel(errJobCancelled)
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() { cancel(nil) }
 }
 
 // ------------------------
 // S3 helpers
 // ------------------------
 
-func (w *Worker) downloadFromS3(ctx context.Context, key, dstPath string) error {
+func (w *Worker) downloadFromS3(ctx context.Context, bucket, key, dstPath string) error {
 	out, err := w.s3.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(w.s3Bucket),
+		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
@@ -194,7 +334,7 @@ func (w *Worker) downloadFromS3(ctx context.Context, key, dstPath string) error
 	return err
 }
 
-func (w *Worker) uploadDirToS3(ctx context.Context, dir string, s3Prefix string) error {
+func (w *Worker) uploadDirToS3(ctx context.Context, bucket, dir string, s3Prefix string) error {
 	// Upload all files in dir recursively
 	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -226,7 +366,7 @@ func (w *Worker) uploadDirToS3(ctx context.Context, dir string, s3Prefix string)
 		defer f.Close()
 
 		_, err = w.s3.PutObject(ctx, &s3.PutObjectInput{
-			Bucket:      aws.String(w.s3Bucket),
+			Bucket:      aws.String(bucket),
 			Key:         aws.String(key),
 			Body:        f,
 			ContentType: aws.String(ct),
@@ -235,139 +375,11 @@ func (w *Worker) uploadDirToS3(ctx context.Context, dir string, s3Prefix string)
 	})
 }
 
-
-func (w *Worker) transcodeToHLS(ctx context.Context, inputPath, outDir string, log *zap.SugaredLogger) error {
-	
-	// master := filepath.Join(outDir, "master.m3u8")
-
-	// args := []string{
-	// 	"-y",
-	// 	"-i", inputPath,
-
-	// 	// create 3 video streams
-	// 	"-filter_complex",
-	// 	"[0:v]split=3[v480][v720][v1080];" +
-	// 		"[v480]scale=w=854:h=480:force_original_aspect_ratio=decrease[v480out];" +
-	// 		"[v720]scale=w=1280:h=720:force_original_aspect_ratio=decrease[v720out];" +
-	// 		"[v1080]scale=w=1920:h=1080:force_original_aspect_ratio=decrease[v1080out]",
-
-	// 	// map streams
-	// 	"-map", "[v480out]", "-map", "0:a?",
-	// 	"-map", "[v720out]", "-map", "0:a?",
-	// 	"-map", "[v1080out]", "-map", "0:a?",
-
-	// 	// video codecs per rendition (H.264)
-	// 	"-c:v:0", "libx264", "-b:v:0", "800k", "-maxrate:v:0", "900k", "-bufsize:v:0", "1200k",
-	// 	"-c:v:1", "libx264", "-b:v:1", "2000k", "-maxrate:v:1", "2200k", "-bufsize:v:1", "3000k",
-	// 	"-c:v:2", "libx264", "-b:v:2", "4500k", "-maxrate:v:2", "5000k", "-bufsize:v:2", "6500k",
-
-	// 	// audio codec (AAC). "a?" means optional audio; if no audio, it won’t fail.
-	// 	"-c:a", "aac", "-b:a", "128k",
-
-	// 	// HLS settings
-	// 	"-f", "hls",
-	// 	"-hls_time", "4",
-	// 	"-hls_playlist_type", "vod",
-	// 	"-hls_flags", "independent_segments",
-	// 	"-hls_segment_filename", filepath.Join(outDir, "%v_%03d.ts"),
-
-	// 	// variant playlists output pattern
-	// 	"-master_pl_name", filepath.Base(master),
-	// 	"-var_stream_map", "v:0,a:0 v:1,a:1 v:2,a:2",
-	// 	filepath.Join(outDir, "%v.m3u8"),
-	// }
-
-	// cmd := exec.CommandContext(ctx, "ffmpeg", args...)
-	// cmd.Dir = outDir
-
-	// // Capture stderr for debugging
-	// var stderr bytes.Buffer
-	// cmd.Stderr = &stderr
-	// cmd.Stdout = &stderr // ffmpeg logs mostly on stderr anyway
-
-	// if err := cmd.Start(); err != nil {
-	// 	return fmt.Errorf("ffmpeg start: %w", err)
-	// }
-
-	// // stream logs to zap while running (optional)
-	// go func() {
-	// 	sc := bufio.NewScanner(bytes.NewReader(stderr.Bytes()))
-	// 	for sc.Scan() {
-	// 		log.Debug(sc.Text())
-	// 	}
-	// }()
-
-	// err := cmd.Wait()
-	// if err != nil {
-	// 	// include ffmpeg output
-	// 	out := strings.TrimSpace(stderr.String())
-	// 	if out == "" {
-	// 		out = "no ffmpeg output"
-	// 	}
-	// 	return fmt.Errorf("ffmpeg failed: %w | output: %s", err, out)
-	// }
-
-	// // sanity check: master must exist
-	// if _, err := os.Stat(master); err != nil {
-	// 	if errors.Is(err, os.ErrNotExist) {
-	// 		return fmt.Errorf("master playlist missing after transcode")
-	// 	}
-	// 	return err
-	// }
-
-	// return nil
+func (w *Worker) transcodeToHLS(ctx context.Context, inputPath, outDir string, l []rendition, opts types.JobOptions, log *zap.SugaredLogger) error {
 	master := filepath.Join(outDir, "master.m3u8")
 
-	filter :=
-		"[0:v]split=3[v480][v720][v1080];" +
-			"[v480]scale=w=854:h=480:force_original_aspect_ratio=decrease," +
-			"scale=trunc(iw/2)*2:trunc(ih/2)*2[v480out];" +
-			"[v720]scale=w=1280:h=720:force_original_aspect_ratio=decrease," +
-			"scale=trunc(iw/2)*2:trunc(ih/2)*2[v720out];" +
-			"[v1080]scale=w=1920:h=1080:force_original_aspect_ratio=decrease," +
-			"scale=trunc(iw/2)*2:trunc(ih/2)*2[v1080out]"
-
-	args := []string{
-		"-y",
-		"-i", inputPath,
-
-		"-filter_complex", filter,
-
-		// map video+audio
-		"-map", "[v480out]", "-map", "0:a?",
-		"-map", "[v720out]", "-map", "0:a?",
-		"-map", "[v1080out]", "-map", "0:a?",
-
-		// video encode
-		"-c:v:0", "libx264", "-profile:v:0", "high", "-pix_fmt", "yuv420p",
-		"-b:v:0", "800k", "-maxrate:v:0", "900k", "-bufsize:v:0", "1200k",
-
-		"-c:v:1", "libx264", "-profile:v:1", "high", "-pix_fmt", "yuv420p",
-		"-b:v:1", "2000k", "-maxrate:v:1", "2200k", "-bufsize:v:1", "3000k",
-
-		"-c:v:2", "libx264", "-profile:v:2", "high", "-pix_fmt", "yuv420p",
-		"-b:v:2", "4500k", "-maxrate:v:2", "5000k", "-bufsize:v:2", "6500k",
-
-		// audio
-		"-c:a", "aac", "-b:a", "128k", "-ac", "2",
-
-		// GOP alignment for HLS (30fps * 4s = 120)
-		"-g", "120",
-		"-keyint_min", "120",
-		"-sc_threshold", "0",
-
-		// HLS output
-		"-f", "hls",
-		"-hls_time", "4",
-		"-hls_playlist_type", "vod",
-		"-hls_flags", "independent_segments",
-		"-hls_segment_filename", filepath.Join(outDir, "%v_%03d.ts"),
-
-		"-master_pl_name", filepath.Base(master),
-		"-var_stream_map", "v:0,a:0 v:1,a:1 v:2,a:2",
-
-		filepath.Join(outDir, "%v.m3u8"),
-	}
+	args := hlsArgs(inputPath, outDir, l, opts)
+	log.Debugw("ffmpeg", "args", args)
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Dir = outDir
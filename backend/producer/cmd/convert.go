@@ -0,0 +1,136 @@
+package main
+
+import (
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+
+	pb "video-encoding/shared/proto/job"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func optionsFromPB(o *pb.JobOptions) types.JobOptions {
+	var out types.JobOptions
+	if o == nil {
+		return out
+	}
+
+	switch o.GetLadderProfile() {
+	case pb.LadderProfile_LADDER_PROFILE_STANDARD:
+		out.LadderProfile = types.LadderStandard
+	case pb.LadderProfile_LADDER_PROFILE_MOBILE:
+		out.LadderProfile = types.LadderMobile
+	case pb.LadderProfile_LADDER_PROFILE_HD:
+		out.LadderProfile = types.LadderHD
+	}
+
+	switch o.GetVideoCodec() {
+	case pb.VideoCodec_VIDEO_CODEC_H264:
+		out.VideoCodec = types.VideoCodecH264
+	case pb.VideoCodec_VIDEO_CODEC_H265:
+		out.VideoCodec = types.VideoCodecH265
+	}
+
+	switch o.GetAudioCodec() {
+	case pb.AudioCodec_AUDIO_CODEC_AAC:
+		out.AudioCodec = types.AudioCodecAAC
+	case pb.AudioCodec_AUDIO_CODEC_AC3:
+		out.AudioCodec = types.AudioCodecAC3
+	case pb.AudioCodec_AUDIO_CODEC_NONE:
+		out.AudioCodec = types.AudioCodecNone
+	}
+
+	switch o.GetPriority() {
+	case pb.JobPriority_JOB_PRIORITY_LOW:
+		out.Priority = types.PriorityLow
+	case pb.JobPriority_JOB_PRIORITY_NORMAL:
+		out.Priority = types.PriorityNormal
+	case pb.JobPriority_JOB_PRIORITY_HIGH:
+		out.Priority = types.PriorityHigh
+	}
+
+	out.SegmentSeconds = int(o.GetSegmentDurationSeconds())
+	return out
+}
+
+func optionsToPB(o types.JobOptions) *pb.JobOptions {
+	o = o.WithDefaults()
+	out := &pb.JobOptions{SegmentDurationSeconds: uint32(o.SegmentSeconds)}
+
+	switch o.LadderProfile {
+	case types.LadderStandard:
+		out.LadderProfile = pb.LadderProfile_LADDER_PROFILE_STANDARD
+	case types.LadderMobile:
+		out.LadderProfile = pb.LadderProfile_LADDER_PROFILE_MOBILE
+	case types.LadderHD:
+		out.LadderProfile = pb.LadderProfile_LADDER_PROFILE_HD
+	}
+
+	switch o.VideoCodec {
+	case types.VideoCodecH264:
+		out.VideoCodec = pb.VideoCodec_VIDEO_CODEC_H264
+	case types.VideoCodecH265:
+		out.VideoCodec = pb.VideoCodec_VIDEO_CODEC_H265
+	}
+
+	switch o.AudioCodec {
+	case types.AudioCodecAAC:
+		out.AudioCodec = pb.AudioCodec_AUDIO_CODEC_AAC
+	case types.AudioCodecAC3:
+		out.AudioCodec = pb.AudioCodec_AUDIO_CODEC_AC3
+	case types.AudioCodecNone:
+		out.AudioCodec = pb.AudioCodec_AUDIO_CODEC_NONE
+	}
+
+	switch o.Priority {
+	case types.PriorityLow:
+		out.Priority = pb.JobPriority_JOB_PRIORITY_LOW
+	case types.PriorityNormal:
+		out.Priority = pb.JobPriority_JOB_PRIORITY_NORMAL
+	case types.PriorityHigh:
+		out.Priority = pb.JobPriority_JOB_PRIORITY_HIGH
+	}
+
+	return out
+}
+
+func jobStatusToPB(s store.JobStatus) pb.JobStatus {
+	switch s {
+	case store.JobQueued:
+		return pb.JobStatus_JOB_STATUS_QUEUED
+	case store.JobProcessing:
+		return pb.JobStatus_JOB_STATUS_PROCESSING
+	case store.JobCompleted:
+		return pb.JobStatus_JOB_STATUS_COMPLETED
+	case store.JobFailed:
+		return pb.JobStatus_JOB_STATUS_FAILED
+	case store.JobCancelled:
+		return pb.JobStatus_JOB_STATUS_CANCELLED
+	default:
+		return pb.JobStatus_JOB_STATUS_UNSPECIFIED
+	}
+}
+
+func jobToPB(j store.Job) *pb.TranscodeJob {
+	out := &pb.TranscodeJob{
+		JobId:     j.ID,
+		VideoId:   j.VideoID,
+		InputKey:  j.InputKey,
+		Status:    jobStatusToPB(j.Status),
+		Progress:  int32(j.Progress),
+		Options:   optionsToPB(j.Options),
+		CreatedAt: timestamppb.New(j.CreatedAt),
+		UpdatedAt: timestamppb.New(j.UpdatedAt),
+		Outputs: &pb.JobOutputs{
+			PlaybackReady:       j.PlaybackReady,
+			AvailableRenditions: j.AvailableRenditions,
+		},
+	}
+	if j.ErrorMsg != nil {
+		out.ErrorMessage = *j.ErrorMsg
+	}
+	if j.OutputMasterKey != nil {
+		out.Outputs.MasterKey = *j.OutputMasterKey
+	}
+	return out
+}
@@ -2,68 +2,229 @@ package main
 
 import (
 	"context"
-	"time"
-	kafka "video-encoding/producer/internal"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"video-encoding/shared/events"
+	"video-encoding/shared/jobwatch"
+	"video-encoding/shared/store"
 	"video-encoding/shared/types"
 
-	pb "video-encoding/shared/proto/job" 
+	pb "video-encoding/shared/proto/job"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const maxBatchSize = 100
+
 type grpcHandler struct {
-	
-    pb.UnimplementedJobProducerServiceServer
-	producer *kafka.Producer
-	topic    string
+	pb.UnimplementedJobProducerServiceServer
+	store store.Storage
+	watch *jobwatch.Hub // nil when notifications are disabled
 }
 
-func NewGrpcHandler(s *grpc.Server,prod *kafka.Producer, topic string) {
+func NewGrpcHandler(s *grpc.Server, st store.Storage, watch *jobwatch.Hub) {
 	handler := &grpcHandler{
-		producer: prod,
-		topic:    topic,
+		store: st,
+		watch: watch,
 	}
 
 	pb.RegisterJobProducerServiceServer(s, handler)
-	
-	
 }
 
-
+// EnqueueTranscodeJob creates the job row together with its outbox entry;
+// the outbox relay does the actual publishing.
 func (s *grpcHandler) EnqueueTranscodeJob(ctx context.Context, req *pb.EnqueueTranscodeJobRequest) (*pb.EnqueueTranscodeJobResponse, error) {
-	if req.GetJobId() == "" || req.GetVideoId() == "" || req.GetInputKey() == "" {
+	jobID, err := s.enqueue(ctx, req)
+	if err != nil {
 		return &pb.EnqueueTranscodeJobResponse{
 			Accepted: false,
-			Message:  "job_id, video_id, input_key are required",
+			Message:  err.Error(),
+			JobId:    jobID,
 		}, nil
 	}
 
+	return &pb.EnqueueTranscodeJobResponse{
+		Accepted: true,
+		Message:  "enqueued",
+		JobId:    jobID,
+	}, nil
+}
+
+func (s *grpcHandler) BatchEnqueueTranscodeJobs(ctx context.Context, req *pb.BatchEnqueueTranscodeJobsRequest) (*pb.BatchEnqueueTranscodeJobsResponse, error) {
+	if len(req.GetJobs()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "jobs is required")
+	}
+	if len(req.GetJobs()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d jobs per batch", maxBatchSize)
+	}
+
+	resp := &pb.BatchEnqueueTranscodeJobsResponse{
+		Results: make([]*pb.BatchEnqueueResult, 0, len(req.GetJobs())),
+	}
+
+	// each item is its own transaction: one bad item doesn't sink the batch
+	for i, item := range req.GetJobs() {
+		res := &pb.BatchEnqueueResult{Index: int32(i)}
+
+		jobID, err := s.enqueue(ctx, item)
+		res.JobId = jobID
+		if err != nil {
+			res.Message = err.Error()
+		} else {
+			res.Accepted = true
+			res.Message = "enqueued"
+			resp.AcceptedCount++
+		}
+		resp.Results = append(resp.Results, res)
+	}
+
+	return resp, nil
+}
+
+func (s *grpcHandler) CancelTranscodeJob(ctx context.Context, req *pb.CancelTranscodeJobRequest) (*pb.CancelTranscodeJobResponse, error) {
+	if req.GetJobId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+
+	// the cancellation and its event are written together
+	var st store.JobStatus
+	err := s.store.WithTx(ctx, func(tx store.Storage) error {
+		var err error
+		if st, err = tx.Job.Cancel(ctx, req.GetJobId(), req.GetReason()); err != nil || st != store.JobCancelled {
+			return err
+		}
+		j, err := tx.Job.Get(ctx, req.GetJobId())
+		if err != nil {
+			return err
+		}
+		return events.Record(ctx, tx, events.New(events.JobCancelled, events.SourceProducer, j.VideoID, j.ID, events.JobState{
+			Status:     string(store.JobCancelled),
+			Progress:   j.Progress,
+			Renditions: j.AvailableRenditions,
+			Error:      req.GetReason(),
+		}))
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "job not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if st != store.JobCancelled {
+		return &pb.CancelTranscodeJobResponse{
+			Cancelled: false,
+			Status:    jobStatusToPB(st),
+			Message:   "job is already " + string(st),
+		}, nil
+	}
+
+	return &pb.CancelTranscodeJobResponse{
+		Cancelled: true,
+		Status:    pb.JobStatus_JOB_STATUS_CANCELLED,
+		Message:   "cancelled",
+	}, nil
+}
+
+func (s *grpcHandler) GetTranscodeJob(ctx context.Context, req *pb.GetTranscodeJobRequest) (*pb.GetTranscodeJobResponse, error) {
+	if req.GetJobId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+
+	j, err := s.store.Job.Get(ctx, req.GetJobId())
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "job not found")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.GetTranscodeJobResponse{Job: jobToPB(j)}, nil
+}
+
+func (s *grpcHandler) enqueue(ctx context.Context, req *pb.EnqueueTranscodeJobRequest) (string, error) {
+	jobID := strings.TrimSpace(req.GetJobId())
+	if jobID == "" {
+		jobID = uuid.NewString()
+	}
+
+	if req.GetVideoId() == "" {
+		return jobID, errors.New("video_id is required")
+	}
+
 	pipeline := req.GetPipeline()
 	if pipeline == "" {
-		pipeline = "hls"
+		pipeline = types.PipelineHLS
+	}
+	if err := types.ValidatePipeline(pipeline); err != nil {
+		return jobID, err
+	}
+
+	opts := optionsFromPB(req.GetOptions())
+	if err := opts.Validate(); err != nil {
+		return jobID, err
+	}
+
+	// the job goes to the video's tenant, whose bucket holds the input
+	v, err := s.store.Video.Get(ctx, req.GetVideoId())
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return jobID, errors.New("video not found")
+		}
+		return jobID, err
+	}
+	inputKey := req.GetInputKey()
+	if inputKey == "" {
+		inputKey = v.InputKey
 	}
 
-	// publish to kafka
-	msg := types.TranscodeJobMessage{
-		JobID:    req.GetJobId(),
+	payload, err := json.Marshal(types.TranscodeJobMessage{
+		JobID:    jobID,
+		TenantID: v.TenantID,
 		VideoID:  req.GetVideoId(),
-		InputKey: req.GetInputKey(),
+		InputKey: inputKey,
 		Pipeline: pipeline,
+		Options:  opts,
+	})
+	if err != nil {
+		return jobID, err
 	}
 
-	pctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	if err := s.producer.PublishJSON(pctx, s.topic, msg.JobID, msg); err != nil {
-		
-		return &pb.EnqueueTranscodeJobResponse{
-			Accepted: false,
-			Message:  "kafka publish failed: " + err.Error(),
-		}, nil
+	withDefaults := opts.WithDefaults()
+	queued, err := events.New(events.JobQueued, events.SourceProducer, req.GetVideoId(), jobID, events.JobState{
+		Status:  string(store.JobQueued),
+		Options: &withDefaults,
+	}).Outbox()
+	if err != nil {
+		return jobID, err
 	}
 
-	return &pb.EnqueueTranscodeJobResponse{
-		Accepted: true,
-		Message:  "enqueued",
-	}, nil
-}
\ No newline at end of file
+	// the gRPC request has no conflict policy; queue never drops a job
+	_, err = s.store.Job.Enqueue(ctx, store.Job{
+		ID:       jobID,
+		TenantID: v.TenantID,
+		VideoID:  req.GetVideoId(),
+		InputKey: inputKey,
+		Pipeline: pipeline,
+		Options:  opts,
+		Status:   store.JobQueued,
+	}, store.JobConflictQueue, store.OutboxEntry{
+		Kind:    store.OutboxTranscodeJob,
+		Key:     jobID,
+		Payload: payload,
+	}, queued)
+	switch {
+	case errors.Is(err, store.ErrDuplicate):
+		return jobID, errors.New("job already exists")
+	case errors.Is(err, store.ErrNotFound):
+		return jobID, errors.New("video not found")
+	case err != nil:
+		return jobID, err
+	}
+	return jobID, nil
+}
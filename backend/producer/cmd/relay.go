@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"video-encoding/shared/events"
+	"video-encoding/shared/queue"
+	"video-encoding/shared/queue/kafka"
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+
+	ckafka "github.com/confluentinc/confluent-kafka-go/kafka"
+	"go.uber.org/zap"
+)
+
+// publishTimeout bounds the publish of one outbox entry.
+const publishTimeout = 5 * time.Second
+
+// outboxRelay drains the outbox table into the job queue and the lifecycle
+// events topic. The API only ever writes outbox rows, so a job is published
+// even if the producer was down when it was created.
+type outboxRelay struct {
+	log         *zap.SugaredLogger
+	store       store.Storage
+	queue       queue.JobQueue
+	events      *kafka.Producer // nil when events are disabled
+	eventsTopic string
+	interval    time.Duration
+	batch       int
+	maxAttempts int // then the entry is marked dead; 0: retry forever
+}
+
+func (r *outboxRelay) Run(ctx context.Context) {
+	for {
+		// the lease outlasts a batch of timeouts, so no other relay
+		// publishes an entry this one is still working on
+		lease := time.Duration(r.batch)*publishTimeout + time.Minute
+		n, err := r.store.Outbox.Relay(ctx, r.batch, r.maxAttempts, lease, r.publish)
+		if err != nil && ctx.Err() == nil {
+			r.log.Warnw("outbox relay failed", "err", err)
+		}
+
+		// a full batch means there is probably more waiting
+		if n == r.batch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+func (r *outboxRelay) publish(ctx context.Context, e store.OutboxEntry) error {
+	err := r.send(ctx, e)
+	if err != nil && r.maxAttempts > 0 && e.Attempts >= r.maxAttempts {
+		r.log.Errorw("outbox entry marked dead", "id", e.ID, "kind", e.Kind, "key", e.Key, "attempts", e.Attempts, "err", err)
+	}
+	return err
+}
+
+func (r *outboxRelay) send(ctx context.Context, e store.OutboxEntry) error {
+	pctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	switch e.Kind {
+	case store.OutboxTranscodeJob:
+		var msg types.TranscodeJobMessage
+		if err := json.Unmarshal(e.Payload, &msg); err != nil {
+			return fmt.Errorf("decode outbox %d: %w", e.ID, err)
+		}
+		if err := r.queue.Publish(pctx, msg); err != nil {
+			r.log.Warnw("publish job failed", "jobId", msg.JobID, "attempt", e.Attempts, "err", err)
+			return err
+		}
+		return nil
+	case store.OutboxJobEvent:
+		if r.events == nil {
+			return nil // disabled: mark sent so the outbox doesn't grow
+		}
+		var ev events.Event
+		if err := json.Unmarshal(e.Payload, &ev); err != nil {
+			return fmt.Errorf("decode outbox %d: %w", e.ID, err)
+		}
+		headers := []ckafka.Header{
+			{Key: events.HeaderSchemaVersion, Value: []byte(strconv.Itoa(ev.SchemaVersion))},
+			{Key: events.HeaderEventType, Value: []byte(ev.Type)},
+		}
+		if err := r.events.Publish(pctx, r.eventsTopic, e.Key, e.Payload, headers); err != nil {
+			r.log.Warnw("publish event failed", "eventId", ev.ID, "attempt", e.Attempts, "err", err)
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown outbox kind %q", e.Kind)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"video-encoding/shared/store"
+
+	pb "video-encoding/shared/proto/job"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// resyncEvery re-reads the job even without a notification, in case one
+// was lost while the listener was reconnecting.
+const resyncEvery = 15 * time.Second
+
+func (s *grpcHandler) WatchJob(req *pb.WatchJobRequest, stream grpc.ServerStreamingServer[pb.JobUpdate]) error {
+	if req.GetJobId() == "" {
+		return status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	if s.watch == nil {
+		return status.Error(codes.Unavailable, "job notifications are not enabled")
+	}
+
+	ctx := stream.Context()
+
+	// subscribe before the first read so no change slips in between
+	updates, unsubscribe := s.watch.SubscribeJob(req.GetJobId())
+	defer unsubscribe()
+
+	tick := time.NewTicker(resyncEvery)
+	defer tick.Stop()
+
+	var last *pb.JobUpdate
+	for {
+		j, err := s.store.Job.Get(ctx, req.GetJobId())
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return status.Error(codes.NotFound, "job not found")
+			}
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		u := jobUpdateToPB(j)
+		if last == nil || changed(last, u) {
+			if err := stream.Send(u); err != nil {
+				return err
+			}
+			last = u
+		}
+		if u.GetTerminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-updates:
+		case <-tick.C:
+		}
+	}
+}
+
+func jobUpdateToPB(j store.Job) *pb.JobUpdate {
+	u := &pb.JobUpdate{
+		JobId:               j.ID,
+		VideoId:             j.VideoID,
+		Status:              jobStatusToPB(j.Status),
+		Progress:            int32(j.Progress),
+		AvailableRenditions: j.AvailableRenditions,
+		PlaybackReady:       j.PlaybackReady,
+		UpdatedAt:           timestamppb.New(j.UpdatedAt),
+	}
+	if j.OutputMasterKey != nil {
+		u.MasterKey = *j.OutputMasterKey
+	}
+
+	switch j.Status {
+	case store.JobCompleted, store.JobFailed, store.JobCancelled:
+		u.Terminal = true
+		if j.Status != store.JobCompleted && j.ErrorMsg != nil {
+			u.ErrorMessage = *j.ErrorMsg
+		}
+	}
+	return u
+}
+
+func changed(a, b *pb.JobUpdate) bool {
+	return a.GetStatus() != b.GetStatus() ||
+		a.GetProgress() != b.GetProgress() ||
+		a.GetPlaybackReady() != b.GetPlaybackReady() ||
+		a.GetMasterKey() != b.GetMasterKey() ||
+		a.GetErrorMessage() != b.GetErrorMessage() ||
+		!slices.Equal(a.GetAvailableRenditions(), b.GetAvailableRenditions())
+}
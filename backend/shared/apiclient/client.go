@@ -0,0 +1,194 @@
+// Package apiclient is a Go client for the /v1 API. Requests and responses
+// are the structs of the types package, the same ones the handlers use, and
+// each method is named after the operationId in /v1/openapi.json. The S3
+// notification endpoint is for object storage, not callers, and is left out.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	httpx "video-encoding/shared/response"
+	"video-encoding/shared/types"
+)
+
+// Client calls the API as one caller. Set APIKey or Token (a JWT) before
+// the first call; Tenant picks the tenant of an API key that may act for
+// several.
+type Client struct {
+	BaseURL    string // e.g. http://api:8080, without /v1
+	HTTPClient *http.Client
+
+	APIKey string
+	Token  string
+	Tenant string
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Error is a failed call: the status and the error of the envelope.
+type Error struct {
+	Status  int
+	Code    string
+	Details string
+	// RetryAfter is set on 429 and on 409 for a request still in flight
+	// under the same Idempotency-Key.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Details == "" {
+		return fmt.Sprintf("api: %d %s", e.Status, e.Code)
+	}
+	return fmt.Sprintf("api: %d %s: %s", e.Status, e.Code, e.Details)
+}
+
+// CallOption sets a header of one call.
+type CallOption func(*http.Request)
+
+// IdempotencyKey makes retries of PresignVideoUpload and CreateVideoJob
+// with the same key replay the first response instead of repeating it.
+func IdempotencyKey(key string) CallOption {
+	return func(r *http.Request) { r.Header.Set("Idempotency-Key", key) }
+}
+
+// IfMatch makes UpdateVideo fail with 412 unless the video still has the
+// ETag returned by GetVideo.
+func IfMatch(etag string) CallOption {
+	return func(r *http.Request) { r.Header.Set("If-Match", etag) }
+}
+
+type envelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Error   *httpx.APIError `json:"error"`
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body any, opts []CallOption) (*http.Request, error) {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.Tenant != "" {
+		req.Header.Set("X-Tenant-ID", c.Tenant)
+	}
+	for _, o := range opts {
+		o(req)
+	}
+	return req, nil
+}
+
+// do sends a request and decodes the data of the envelope into out, which
+// may be nil. It returns the response headers for callers that need them.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any, opts ...CallOption) (http.Header, error) {
+	req, err := c.newRequest(ctx, method, path, query, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return res.Header, responseError(res)
+	}
+
+	var env envelope
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return res.Header, fmt.Errorf("api: decode %s %s: %w", method, path, err)
+	}
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return res.Header, fmt.Errorf("api: decode %s %s: %w", method, path, err)
+		}
+	}
+	return res.Header, nil
+}
+
+func responseError(res *http.Response) error {
+	e := &Error{Status: res.StatusCode, Code: http.StatusText(res.StatusCode)}
+	if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(s) * time.Second
+	}
+
+	var env envelope
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if json.Unmarshal(b, &env) == nil && env.Error != nil {
+		e.Code = env.Error.Code
+		e.Details = env.Error.Details
+	}
+	return e
+}
+
+func pathf(format string, ids ...string) string {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = url.PathEscape(id)
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// GetUsage returns the caller's usage against their quotas.
+func (c *Client) GetUsage(ctx context.Context) (types.UsageResp, error) {
+	var out types.UsageResp
+	_, err := c.do(ctx, http.MethodGet, "/v1/usage", nil, nil, &out)
+	return out, err
+}
+
+// GetOpenAPI returns the OpenAPI document of the server. It is the only
+// response without an envelope.
+func (c *Client) GetOpenAPI(ctx context.Context) (json.RawMessage, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/openapi.json", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return nil, responseError(res)
+	}
+	return io.ReadAll(res.Body)
+}
@@ -0,0 +1,40 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"video-encoding/shared/types"
+)
+
+// ListVideoJobs returns the jobs of a video, newest first. A zero limit
+// uses the server's default.
+func (c *Client) ListVideoJobs(ctx context.Context, id string, limit, offset int) (types.JobListResp, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+	var out types.JobListResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/videos/%s/jobs", id), q, nil, &out)
+	return out, err
+}
+
+// CreateVideoJob enqueues a job. With req.OnConflict unset it fails with
+// 409 JOB_ACTIVE while another job of the video is queued or processing.
+// Pass IdempotencyKey to retry it safely.
+func (c *Client) CreateVideoJob(ctx context.Context, id string, req types.CreateVideoJobReq, opts ...CallOption) (types.CreateVideoJobResp, error) {
+	var out types.CreateVideoJobResp
+	_, err := c.do(ctx, http.MethodPost, pathf("/v1/videos/%s/jobs", id), nil, req, &out, opts...)
+	return out, err
+}
+
+func (c *Client) GetJob(ctx context.Context, jobID string) (types.JobDetailResp, error) {
+	var out types.JobDetailResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/jobs/%s", jobID), nil, nil, &out)
+	return out, err
+}
@@ -0,0 +1,68 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+
+	"video-encoding/shared/types"
+)
+
+// PresignVideoUpload creates a video and returns presigned PUT URLs for
+// its input and thumbnail. Pass IdempotencyKey to retry it safely.
+func (c *Client) PresignVideoUpload(ctx context.Context, req types.PresignVideoUploadReq, opts ...CallOption) (types.PresignVideoUploadResp, error) {
+	var out types.PresignVideoUploadResp
+	_, err := c.do(ctx, http.MethodPost, "/v1/videos/presign", nil, req, &out, opts...)
+	return out, err
+}
+
+// CompleteUpload verifies the uploaded input and, with req.Enqueue,
+// enqueues a job for it.
+func (c *Client) CompleteUpload(ctx context.Context, id string, req types.CompleteUploadReq) (types.CompleteUploadResp, error) {
+	var out types.CompleteUploadResp
+	_, err := c.do(ctx, http.MethodPost, pathf("/v1/videos/%s/complete", id), nil, req, &out)
+	return out, err
+}
+
+func (c *Client) StartMultipartUpload(ctx context.Context, req types.StartMultipartUploadReq) (types.StartMultipartUploadResp, error) {
+	var out types.StartMultipartUploadResp
+	_, err := c.do(ctx, http.MethodPost, "/v1/videos/multipart", nil, req, &out)
+	return out, err
+}
+
+// GetMultipartUpload lists the parts uploaded so far, to resume an upload.
+func (c *Client) GetMultipartUpload(ctx context.Context, id string) (types.MultipartUploadResp, error) {
+	var out types.MultipartUploadResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/videos/%s/multipart", id), nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) PresignUploadParts(ctx context.Context, id string, req types.PresignPartsReq) (types.PresignPartsResp, error) {
+	var out types.PresignPartsResp
+	_, err := c.do(ctx, http.MethodPost, pathf("/v1/videos/%s/multipart/parts", id), nil, req, &out)
+	return out, err
+}
+
+func (c *Client) CompleteMultipartUpload(ctx context.Context, id string, req types.CompleteMultipartUploadReq) (types.CompleteUploadResp, error) {
+	var out types.CompleteUploadResp
+	_, err := c.do(ctx, http.MethodPost, pathf("/v1/videos/%s/multipart/complete", id), nil, req, &out)
+	return out, err
+}
+
+func (c *Client) AbortMultipartUpload(ctx context.Context, id string) (types.MultipartUploadResp, error) {
+	var out types.MultipartUploadResp
+	_, err := c.do(ctx, http.MethodDelete, pathf("/v1/videos/%s/multipart", id), nil, nil, &out)
+	return out, err
+}
+
+// ImportVideo creates a video the server fetches from req.SourceURL.
+func (c *Client) ImportVideo(ctx context.Context, req types.ImportVideoReq) (types.ImportVideoResp, error) {
+	var out types.ImportVideoResp
+	_, err := c.do(ctx, http.MethodPost, "/v1/videos/import", nil, req, &out)
+	return out, err
+}
+
+func (c *Client) GetVideoImport(ctx context.Context, id string) (types.ImportResp, error) {
+	var out types.ImportResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/videos/%s/import", id), nil, nil, &out)
+	return out, err
+}
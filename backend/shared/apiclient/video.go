@@ -0,0 +1,146 @@
+package apiclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"video-encoding/shared/types"
+)
+
+// VideoListParams filters and pages ListVideos. Zero fields are left to
+// the server's defaults.
+type VideoListParams struct {
+	Query        string   // full-text search in title and description
+	Status       []string // any of these statuses
+	Sort         string   // created_at, -created_at, title, -title, size or -size
+	Limit        int
+	Cursor       string // NextCursor of the previous page
+	IncludeTotal bool
+	Owner        string // admins only
+}
+
+func (p VideoListParams) values() url.Values {
+	q := url.Values{}
+	if p.Query != "" {
+		q.Set("q", p.Query)
+	}
+	if len(p.Status) > 0 {
+		q.Set("status", strings.Join(p.Status, ","))
+	}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	if p.IncludeTotal {
+		q.Set("includeTotal", "true")
+	}
+	if p.Owner != "" {
+		q.Set("owner", p.Owner)
+	}
+	return q
+}
+
+func (c *Client) ListVideos(ctx context.Context, p VideoListParams) (types.VideoListResp, error) {
+	var out types.VideoListResp
+	_, err := c.do(ctx, http.MethodGet, "/v1/videos", p.values(), nil, &out)
+	return out, err
+}
+
+// GetVideo returns the video and its ETag, for IfMatch in UpdateVideo.
+func (c *Client) GetVideo(ctx context.Context, id string) (types.VideoResp, string, error) {
+	var out types.VideoResp
+	h, err := c.do(ctx, http.MethodGet, pathf("/v1/videos/%s", id), nil, nil, &out)
+	if err != nil {
+		return out, "", err
+	}
+	return out, h.Get("ETag"), nil
+}
+
+// UpdateVideo needs IfMatch or req.UpdatedAt as the precondition.
+func (c *Client) UpdateVideo(ctx context.Context, id string, req types.UpdateVideoReq, opts ...CallOption) (types.UpdateVideoResp, error) {
+	var out types.UpdateVideoResp
+	_, err := c.do(ctx, http.MethodPatch, pathf("/v1/videos/%s", id), nil, req, &out, opts...)
+	return out, err
+}
+
+// DeleteVideo deletes the video; its objects are purged in the background.
+func (c *Client) DeleteVideo(ctx context.Context, id string) (types.PurgeResp, error) {
+	var out types.PurgeResp
+	_, err := c.do(ctx, http.MethodDelete, pathf("/v1/videos/%s", id), nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) GetVideoPurge(ctx context.Context, id string) (types.PurgeResp, error) {
+	var out types.PurgeResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/videos/%s/purge", id), nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) GetVideoPlayback(ctx context.Context, id string) (types.PlaybackResp, error) {
+	var out types.PlaybackResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/videos/%s/playback", id), nil, nil, &out)
+	return out, err
+}
+
+// VideoEvents follows the Server-Sent Events of a video and calls fn with
+// every playback update, starting with the current state. It returns when
+// ctx is done, the stream ends or fn returns an error, which it returns.
+func (c *Client) VideoEvents(ctx context.Context, id string, fn func(types.PlaybackResp) error) error {
+	req, err := c.newRequest(ctx, http.MethodGet, pathf("/v1/videos/%s/events", id), nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// the stream outlives any request timeout
+	hc := *c.HTTPClient
+	hc.Timeout = 0
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return responseError(res)
+	}
+
+	sc := bufio.NewScanner(res.Body)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	var event string
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if event == "playback" && len(data) > 0 {
+				var p types.PlaybackResp
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &p); err != nil {
+					return err
+				}
+				if err := fn(p); err != nil {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return sc.Err()
+}
@@ -0,0 +1,56 @@
+package apiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"video-encoding/shared/types"
+)
+
+// The webhook calls need an operator.
+
+// CreateWebhook registers a webhook. The response is the only one that
+// carries its secret.
+func (c *Client) CreateWebhook(ctx context.Context, req types.CreateWebhookReq) (types.WebhookResp, error) {
+	var out types.WebhookResp
+	_, err := c.do(ctx, http.MethodPost, "/v1/webhooks", nil, req, &out)
+	return out, err
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]types.WebhookResp, error) {
+	var out []types.WebhookResp
+	_, err := c.do(ctx, http.MethodGet, "/v1/webhooks", nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id string) (types.WebhookResp, error) {
+	var out types.WebhookResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/webhooks/%s", id), nil, nil, &out)
+	return out, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id string, req types.UpdateWebhookReq) (types.WebhookResp, error) {
+	var out types.WebhookResp
+	_, err := c.do(ctx, http.MethodPatch, pathf("/v1/webhooks/%s", id), nil, req, &out)
+	return out, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) (types.DeleteWebhookResp, error) {
+	var out types.DeleteWebhookResp
+	_, err := c.do(ctx, http.MethodDelete, pathf("/v1/webhooks/%s", id), nil, nil, &out)
+	return out, err
+}
+
+// ListWebhookDeliveries returns the latest deliveries with their attempts.
+// A zero limit uses the server's default.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id string, limit int) ([]types.WebhookDeliveryResp, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out []types.WebhookDeliveryResp
+	_, err := c.do(ctx, http.MethodGet, pathf("/v1/webhooks/%s/deliveries", id), q, nil, &out)
+	return out, err
+}
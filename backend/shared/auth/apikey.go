@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+type apiKey struct {
+	hash  [sha256.Size]byte
+	name  string
+	admin bool
+}
+
+// APIKeys are static keys for server-to-server callers.
+type APIKeys []apiKey
+
+// ParseAPIKeys parses "[tenant/]name:key[:admin],..." as found in API_KEYS.
+// A key authenticates as subject "key:<name>" in its tenant.
+func ParseAPIKeys(s string) (APIKeys, error) {
+	var out APIKeys
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("api key %q: expected [tenant/]name:key[:admin]", parts[0])
+		}
+		if len(parts) == 3 && parts[2] != "admin" {
+			return nil, fmt.Errorf("api key %q: unknown role %q", parts[0], parts[2])
+		}
+		out = append(out, apiKey{
+			hash:  sha256.Sum256([]byte(parts[1])),
+			name:  parts[0],
+			admin: len(parts) == 3,
+		})
+	}
+	return out, nil
+}
+
+// Lookup compares key against every configured key in constant time.
+func (ks APIKeys) Lookup(key string) (Principal, error) {
+	h := sha256.Sum256([]byte(key))
+	var found *apiKey
+	for i := range ks {
+		if subtle.ConstantTimeCompare(h[:], ks[i].hash[:]) == 1 {
+			found = &ks[i]
+		}
+	}
+	if found == nil {
+		return Principal{}, ErrUnauthenticated
+	}
+	tenant, _, _ := strings.Cut(found.name, "/")
+	if tenant == found.name {
+		tenant = ""
+	}
+	return Principal{Subject: "key:" + found.name, Tenant: tenant, Admin: found.admin, Method: MethodAPIKey}, nil
+}
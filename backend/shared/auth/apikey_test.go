@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		spec    string
+		n       int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{" , ", 0, false},
+		{"ingest:s3cret", 1, false},
+		{"acme/ingest:s3cret, ops:t0p:admin", 2, false},
+		{"ingest", 0, true},
+		{":s3cret", 0, true},
+		{"ingest:", 0, true},
+		{"ingest:s3cret:root", 0, true},
+		{"ingest:s3cret:admin:extra", 0, true},
+	}
+	for _, tt := range tests {
+		keys, err := ParseAPIKeys(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAPIKeys(%q): err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if len(keys) != tt.n {
+			t.Errorf("ParseAPIKeys(%q): %d keys, want %d", tt.spec, len(keys), tt.n)
+		}
+	}
+}
+
+func TestAPIKeysLookup(t *testing.T) {
+	keys, err := ParseAPIKeys("ingest:k1,acme/ingest:k2,ops:k3:admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want Principal
+	}{
+		{"k1", Principal{Subject: "key:ingest", Method: MethodAPIKey}},
+		{"k2", Principal{Subject: "key:acme/ingest", Tenant: "acme", Method: MethodAPIKey}},
+		{"k3", Principal{Subject: "key:ops", Admin: true, Method: MethodAPIKey}},
+	}
+	for _, tt := range tests {
+		got, err := keys.Lookup(tt.key)
+		if err != nil {
+			t.Errorf("Lookup(%q): %v", tt.key, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, want %+v", tt.key, got, tt.want)
+		}
+	}
+
+	for _, key := range []string{"", "k", "k1 ", "ingest"} {
+		if _, err := keys.Lookup(key); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Lookup(%q): err = %v, want ErrUnauthenticated", key, err)
+		}
+	}
+}
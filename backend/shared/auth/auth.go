@@ -0,0 +1,95 @@
+// Package auth authenticates API callers with JWT bearer tokens (HS256 or
+// RS256) or static API keys and carries the caller in the request context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Method is how a caller authenticated.
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Principal is an authenticated caller. Subject owns the videos it creates;
+// admins see everyone's in their tenant. An empty Tenant is the default
+// tenant.
+type Principal struct {
+	Subject string
+	Tenant  string
+	Admin   bool
+	Method  string
+}
+
+// Anonymous is used when authentication is disabled: it owns nothing and
+// may access everything.
+var Anonymous = Principal{Admin: true}
+
+// CanAccess reports whether p may access a resource owned by owner.
+// Resources without an owner predate authentication and are admin-only.
+func (p Principal) CanAccess(owner *string) bool {
+	if p.Admin {
+		return true
+	}
+	return owner != nil && *owner == p.Subject
+}
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the caller of the request, or Anonymous when the
+// request did not go through the middleware.
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(ctxKey{}).(Principal); ok {
+		return p
+	}
+	return Anonymous
+}
+
+// Authenticator checks the credentials of a request: a bearer JWT, or an
+// API key in X-API-Key (or as a bearer token).
+type Authenticator struct {
+	JWT  *JWTVerifier // nil: no JWTs accepted
+	Keys APIKeys
+}
+
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	return a.authenticate(r, false)
+}
+
+// AuthenticateQuery accepts ?access_token= too, for clients that can't set
+// headers (EventSource). Query strings end up in logs and browser history,
+// so only use it on routes that need it.
+func (a *Authenticator) AuthenticateQuery(r *http.Request) (Principal, error) {
+	return a.authenticate(r, true)
+}
+
+func (a *Authenticator) authenticate(r *http.Request, query bool) (Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.Keys.Lookup(key)
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && query {
+		token = r.URL.Query().Get("access_token")
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	// JWTs have three dot-separated parts, API keys none
+	if strings.Count(token, ".") == 2 && a.JWT != nil {
+		return a.JWT.Verify(token)
+	}
+	return a.Keys.Lookup(token)
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AdminRole in the roles claim, or admin in the space-separated scope
+// claim, makes a token an admin.
+const AdminRole = "admin"
+
+// JWTVerifier accepts HS256 tokens signed with Secret and RS256 tokens
+// signed by one of Keys, looked up by kid.
+type JWTVerifier struct {
+	Secret   []byte
+	Keys     map[string]*rsa.PublicKey
+	Issuer   string // checked when set
+	Audience string // checked when set
+	Leeway   time.Duration
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Roles  []string `json:"roles,omitempty"`
+	Scope  string   `json:"scope,omitempty"`
+	Tenant string   `json:"tenant,omitempty"`
+}
+
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(v.methods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.Leeway),
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.Audience))
+	}
+
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, v.key, opts...)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if c.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no sub", ErrUnauthenticated)
+	}
+
+	admin := slices.Contains(c.Roles, AdminRole) || slices.Contains(strings.Fields(c.Scope), AdminRole)
+	return Principal{Subject: c.Subject, Tenant: c.Tenant, Admin: admin, Method: MethodJWT}, nil
+}
+
+func (v *JWTVerifier) methods() []string {
+	var out []string
+	if len(v.Secret) > 0 {
+		out = append(out, jwt.SigningMethodHS256.Alg())
+	}
+	if len(v.Keys) > 0 {
+		out = append(out, jwt.SigningMethodRS256.Alg())
+	}
+	return out
+}
+
+func (v *JWTVerifier) key(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.Secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := t.Header["kid"].(string)
+		if k, ok := v.Keys[kid]; ok {
+			return k, nil
+		}
+		// a single key needs no kid
+		if kid == "" && len(v.Keys) == 1 {
+			for _, k := range v.Keys {
+				return k, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected alg %s", t.Method.Alg())
+}
+
+// LoadJWKS reads the RSA signing keys of a JWKS file, keyed by kid.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("jwks %s: %w", path, err)
+	}
+
+	out := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") || (k.Alg != "" && k.Alg != "RS256") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwks %s: key %q: bad n: %w", path, k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwks %s: key %q: bad e: %w", path, k.Kid, err)
+		}
+		out[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("jwks %s: no RS256 signing keys", path)
+	}
+	return out, nil
+}
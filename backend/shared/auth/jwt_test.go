@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestJWTVerify(t *testing.T) {
+	secret := []byte("test-secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &JWTVerifier{
+		Secret:   secret,
+		Keys:     map[string]*rsa.PublicKey{"k1": &rsaKey.PublicKey},
+		Issuer:   "https://issuer.example",
+		Audience: "video-api",
+	}
+
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "user-1",
+			"iss": "https://issuer.example",
+			"aud": "video-api",
+			"exp": now.Add(time.Minute).Unix(),
+		}
+	}
+	with := func(k string, val any) jwt.MapClaims {
+		c := valid()
+		if val == nil {
+			delete(c, k)
+		} else {
+			c[k] = val
+		}
+		return c
+	}
+	hs256 := func(c jwt.MapClaims, key []byte) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	rs256 := func(c jwt.MapClaims, kid string) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		if kid != "" {
+			tok.Header["kid"] = kid
+		}
+		s, err := tok.SignedString(rsaKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  *Principal // nil: rejected
+	}{
+		{"hs256", hs256(valid(), secret), &Principal{Subject: "user-1", Method: MethodJWT}},
+		{"tenant", hs256(with("tenant", "acme"), secret), &Principal{Subject: "user-1", Tenant: "acme", Method: MethodJWT}},
+		{"admin role", hs256(with("roles", []string{"viewer", "admin"}), secret), &Principal{Subject: "user-1", Admin: true, Method: MethodJWT}},
+		{"admin scope", hs256(with("scope", "read admin"), secret), &Principal{Subject: "user-1", Admin: true, Method: MethodJWT}},
+		{"scope substring", hs256(with("scope", "administrator"), secret), &Principal{Subject: "user-1", Method: MethodJWT}},
+		{"rs256 kid", rs256(valid(), "k1"), &Principal{Subject: "user-1", Method: MethodJWT}},
+		{"rs256 single key without kid", rs256(valid(), ""), &Principal{Subject: "user-1", Method: MethodJWT}},
+		{"rs256 unknown kid", rs256(valid(), "k2"), nil},
+		{"wrong secret", hs256(valid(), []byte("other")), nil},
+		{"expired", hs256(with("exp", now.Add(-time.Minute).Unix()), secret), nil},
+		{"no exp", hs256(with("exp", nil), secret), nil},
+		{"no sub", hs256(with("sub", nil), secret), nil},
+		{"wrong issuer", hs256(with("iss", "https://evil.example"), secret), nil},
+		{"wrong audience", hs256(with("aud", "other-api"), secret), nil},
+		{"alg none", none, nil},
+		{"garbage", "a.b.c", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Verify(tt.token)
+			if tt.want == nil {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Fatalf("err = %v, want ErrUnauthenticated", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != *tt.want {
+				t.Errorf("got %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestJWTVerifyHS256Only(t *testing.T) {
+	// without Keys, an RS256 token must not be accepted, whatever it says
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}).SignedString(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &JWTVerifier{Secret: []byte("test-secret")}
+	if _, err := v.Verify(tok); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("err = %v, want ErrUnauthenticated", err)
+	}
+}
@@ -1,3 +1,6 @@
+-- -------------------------
+-- videos
+-- -------------------------
 CREATE TABLE IF NOT EXISTS videos (
   id TEXT PRIMARY KEY,
 
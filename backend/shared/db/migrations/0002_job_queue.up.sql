@@ -0,0 +1,18 @@
+-- -------------------------
+-- job_queue (QUEUE_BACKEND=postgres)
+-- -------------------------
+CREATE TABLE IF NOT EXISTS job_queue (
+  id BIGSERIAL PRIMARY KEY,
+  queue TEXT NOT NULL,
+  msg_key TEXT NOT NULL,
+  payload JSONB NOT NULL,
+
+  priority INT NOT NULL DEFAULT 0,
+  attempt INT NOT NULL DEFAULT 0,
+  available_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_until TIMESTAMPTZ,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_queue_ready ON job_queue(queue, priority DESC, id);
@@ -0,0 +1,18 @@
+-- -------------------------
+-- outbox (written in the same tx as the job row, drained by the producer relay)
+-- -------------------------
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGSERIAL PRIMARY KEY,
+  kind TEXT NOT NULL,
+  msg_key TEXT NOT NULL,
+  payload JSONB NOT NULL,
+
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  available_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sent_at TIMESTAMPTZ,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(available_at, id) WHERE sent_at IS NULL;
@@ -0,0 +1,7 @@
+UPDATE jobs SET status='failed' WHERE status='cancelled';
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check
+  CHECK (status IN ('queued','processing','completed','failed'));
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS options;
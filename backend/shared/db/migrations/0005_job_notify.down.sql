@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_jobs_notify ON jobs;
+DROP FUNCTION IF EXISTS notify_job_update();
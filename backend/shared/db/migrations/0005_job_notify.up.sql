@@ -0,0 +1,19 @@
+-- -------------------------
+-- job change notifications (LISTEN job_updates)
+-- -------------------------
+CREATE OR REPLACE FUNCTION notify_job_update() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('job_updates', json_build_object(
+    'jobId', NEW.id,
+    'videoId', NEW.video_id,
+    'status', NEW.status,
+    'progress', NEW.progress
+  )::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_jobs_notify ON jobs;
+CREATE TRIGGER trg_jobs_notify
+  AFTER INSERT OR UPDATE ON jobs
+  FOR EACH ROW EXECUTE FUNCTION notify_job_update();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_video_created;
+DROP TABLE IF EXISTS job_events;
@@ -0,0 +1,26 @@
+-- -------------------------
+-- job_events (timeline of every job: transitions, milestones, errors)
+-- -------------------------
+CREATE TABLE IF NOT EXISTS job_events (
+  id BIGSERIAL PRIMARY KEY,
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  video_id TEXT NOT NULL,
+
+  -- job.queued, job.started, job.progress, job.rendition_ready,
+  -- job.completed, job.failed, job.cancelled
+  type TEXT NOT NULL,
+  status TEXT NOT NULL,
+  progress INT NOT NULL DEFAULT 0,
+
+  -- queue delivery attempt and worker that handled it (empty for API events)
+  attempt INT NOT NULL DEFAULT 0,
+  worker TEXT NOT NULL DEFAULT '',
+
+  error TEXT,
+  details JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_events_job ON job_events(job_id, id);
+CREATE INDEX IF NOT EXISTS idx_jobs_video_created ON jobs(video_id, created_at DESC);
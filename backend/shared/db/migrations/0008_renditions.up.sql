@@ -0,0 +1,28 @@
+-- -------------------------
+-- renditions (one row per HLS variant of a job)
+-- -------------------------
+CREATE TABLE IF NOT EXISTS renditions (
+  job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+  name TEXT NOT NULL, -- 480p, 720p, ...
+
+  video_codec TEXT NOT NULL,
+  audio_codec TEXT NOT NULL,
+  width INT NOT NULL,
+  height INT NOT NULL,
+
+  -- kbps
+  target_bitrate INT NOT NULL,
+  measured_bitrate INT,
+
+  segment_count INT NOT NULL DEFAULT 0,
+  total_bytes BIGINT NOT NULL DEFAULT 0,
+  duration_seconds DOUBLE PRECISION NOT NULL DEFAULT 0,
+  playlist_key TEXT NOT NULL,
+
+  status TEXT NOT NULL CHECK (status IN ('pending','ready','failed')) DEFAULT 'pending',
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (job_id, name)
+);
@@ -0,0 +1,19 @@
+-- -------------------------
+-- purges (S3 cleanup of deleted videos; outlives the video row)
+-- -------------------------
+CREATE TABLE IF NOT EXISTS purges (
+  video_id TEXT PRIMARY KEY,
+  prefixes TEXT[] NOT NULL,
+
+  status TEXT NOT NULL CHECK (status IN ('pending','running','completed','failed')) DEFAULT 'pending',
+  objects_deleted BIGINT NOT NULL DEFAULT 0,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_purges_due ON purges(next_attempt_at) WHERE status IN ('pending','running');
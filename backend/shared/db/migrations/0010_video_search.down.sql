@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_videos_title_id;
+DROP INDEX IF EXISTS idx_videos_duration_id;
+DROP INDEX IF EXISTS idx_videos_created_id;
+CREATE INDEX IF NOT EXISTS idx_videos_created_at ON videos(created_at DESC);
+
+DROP INDEX IF EXISTS idx_videos_search;
+ALTER TABLE videos DROP COLUMN IF EXISTS search;
+ALTER TABLE videos DROP COLUMN IF EXISTS duration_seconds;
@@ -0,0 +1,17 @@
+-- filled by the worker from the encoded renditions
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS duration_seconds DOUBLE PRECISION;
+
+-- full-text search over title (weight A) and description (weight B)
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS search tsvector
+  GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_videos_search ON videos USING GIN (search);
+
+-- keyset pagination: one index per sort order, id breaks ties
+DROP INDEX IF EXISTS idx_videos_created_at;
+CREATE INDEX IF NOT EXISTS idx_videos_created_id ON videos(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_videos_duration_id ON videos((COALESCE(duration_seconds, 0)), id);
+CREATE INDEX IF NOT EXISTS idx_videos_title_id ON videos(lower(title), id);
@@ -0,0 +1,8 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS size_bytes;
+
+UPDATE videos SET status='failed', error_msg='upload never completed' WHERE status='pending_upload';
+
+ALTER TABLE videos ALTER COLUMN status SET DEFAULT 'uploaded';
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_status_check;
+ALTER TABLE videos ADD CONSTRAINT videos_status_check
+  CHECK (status IN ('uploaded','processing','ready','failed'));
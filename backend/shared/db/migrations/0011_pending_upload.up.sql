@@ -0,0 +1,9 @@
+-- videos start as pending_upload until POST /v1/videos/{id}/complete has
+-- verified the uploaded objects
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_status_check;
+ALTER TABLE videos ADD CONSTRAINT videos_status_check
+  CHECK (status IN ('pending_upload','uploaded','processing','ready','failed'));
+ALTER TABLE videos ALTER COLUMN status SET DEFAULT 'pending_upload';
+
+-- verified size of the input object
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS size_bytes BIGINT;
@@ -0,0 +1,3 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS upload_part_size;
+ALTER TABLE videos DROP COLUMN IF EXISTS upload_id;
+ALTER TABLE videos DROP COLUMN IF EXISTS upload_state;
@@ -0,0 +1,8 @@
+-- upload state of the input object; multipart uploads also keep the S3
+-- upload id and part size so clients can resume
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS upload_state TEXT NOT NULL DEFAULT 'pending'
+  CHECK (upload_state IN ('pending','in_progress','completed','aborted'));
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS upload_id TEXT;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS upload_part_size BIGINT;
+
+UPDATE videos SET upload_state='completed' WHERE status <> 'pending_upload';
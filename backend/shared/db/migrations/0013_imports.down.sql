@@ -0,0 +1,7 @@
+UPDATE videos SET upload_state='aborted' WHERE upload_state IN ('importing','failed');
+
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_upload_state_check;
+ALTER TABLE videos ADD CONSTRAINT videos_upload_state_check
+  CHECK (upload_state IN ('pending','in_progress','completed','aborted'));
+
+DROP TABLE IF EXISTS imports;
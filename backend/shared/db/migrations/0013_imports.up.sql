@@ -0,0 +1,30 @@
+-- -------------------------
+-- imports (server-side ingest of a video from a source URL)
+-- -------------------------
+CREATE TABLE IF NOT EXISTS imports (
+  video_id TEXT PRIMARY KEY REFERENCES videos(id) ON DELETE CASCADE,
+  source_url TEXT NOT NULL,
+  checksum_sha256 TEXT, -- expected hex digest, if the client sent one
+
+  -- the job to enqueue once the input is in place
+  enqueue BOOLEAN NOT NULL DEFAULT false,
+  pipeline TEXT NOT NULL DEFAULT 'hls',
+  options JSONB NOT NULL DEFAULT '{}'::jsonb,
+
+  status TEXT NOT NULL CHECK (status IN ('pending','running','completed','failed')) DEFAULT 'pending',
+  bytes_received BIGINT NOT NULL DEFAULT 0,
+  bytes_total BIGINT, -- Content-Length of the source, when known
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_imports_due ON imports(next_attempt_at) WHERE status IN ('pending','running');
+
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_upload_state_check;
+ALTER TABLE videos ADD CONSTRAINT videos_upload_state_check
+  CHECK (upload_state IN ('pending','in_progress','importing','completed','aborted','failed'));
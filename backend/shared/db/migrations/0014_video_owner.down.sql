@@ -0,0 +1,3 @@
+ALTER TABLE purges DROP COLUMN IF EXISTS owner;
+DROP INDEX IF EXISTS idx_videos_owner_created_id;
+ALTER TABLE videos DROP COLUMN IF EXISTS owner;
@@ -0,0 +1,8 @@
+-- subject (JWT sub or key:<name>) that created the video; NULL for videos
+-- created before auth, which only admins can see
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS owner TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_videos_owner_created_id ON videos(owner, created_at DESC, id DESC);
+
+-- kept on the purge so the owner can follow it after the video is gone
+ALTER TABLE purges ADD COLUMN IF NOT EXISTS owner TEXT;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_videos_tenant_owner_created_id;
+DROP INDEX IF EXISTS idx_videos_tenant_created_id;
+CREATE INDEX IF NOT EXISTS idx_videos_owner_created_id ON videos(owner, created_at DESC, id DESC);
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_video_tenant_fkey;
+ALTER TABLE videos DROP CONSTRAINT IF EXISTS videos_id_tenant_key;
+
+ALTER TABLE purges DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE videos DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,14 @@
+-- everything created so far belongs to the default tenant
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE purges ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- a job always belongs to the tenant of its video
+ALTER TABLE videos ADD CONSTRAINT videos_id_tenant_key UNIQUE (id, tenant_id);
+ALTER TABLE jobs ADD CONSTRAINT jobs_video_tenant_fkey
+  FOREIGN KEY (video_id, tenant_id) REFERENCES videos(id, tenant_id) ON DELETE CASCADE;
+
+-- listings are always scoped to a tenant
+DROP INDEX IF EXISTS idx_videos_owner_created_id;
+CREATE INDEX IF NOT EXISTS idx_videos_tenant_created_id ON videos(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_videos_tenant_owner_created_id ON videos(tenant_id, owner, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_tenant_completed;
+DROP INDEX IF EXISTS idx_jobs_tenant_active;
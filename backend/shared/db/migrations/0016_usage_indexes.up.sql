@@ -0,0 +1,3 @@
+-- quota checks count a tenant's active jobs and this month's completed ones
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_active ON jobs(tenant_id) WHERE status IN ('queued','processing');
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_completed ON jobs(tenant_id, updated_at) WHERE status = 'completed';
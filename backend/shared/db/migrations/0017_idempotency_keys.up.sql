@@ -0,0 +1,24 @@
+-- responses of requests sent with an Idempotency-Key, replayed on retries
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  tenant_id TEXT NOT NULL,
+  subject TEXT NOT NULL, -- '' without auth
+  idem_key TEXT NOT NULL,
+
+  -- sha256 of method, path and body
+  fingerprint TEXT NOT NULL,
+
+  status TEXT NOT NULL CHECK (status IN ('in_progress','completed')) DEFAULT 'in_progress',
+  -- an in_progress key whose request died can be taken over after this
+  locked_until TIMESTAMPTZ NOT NULL,
+
+  response_status INT,
+  response_headers JSONB,
+  response_body BYTEA,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+
+  PRIMARY KEY (tenant_id, subject, idem_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_video_active;
+DROP INDEX IF EXISTS idx_outbox_unsent_key;
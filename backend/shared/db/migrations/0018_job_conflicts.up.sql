@@ -0,0 +1,4 @@
+-- queued jobs waiting for another job of their video keep their transcode
+-- entry unsent; enqueue, cancel and release look those up by job id
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent_key ON outbox(kind, msg_key) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_jobs_video_active ON jobs(video_id) WHERE status IN ('queued','processing');
@@ -0,0 +1,16 @@
+-- one row per video created, for the hourly upload quota. Not tied to
+-- videos: deleting a video must not hand its upload back.
+CREATE TABLE IF NOT EXISTS upload_ledger (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id TEXT NOT NULL,
+  owner TEXT,
+  video_id TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_ledger_tenant_created ON upload_ledger(tenant_id, created_at);
+
+-- uploads of the current hour keep counting across the switch
+INSERT INTO upload_ledger (tenant_id, owner, video_id, created_at)
+SELECT tenant_id, owner, id, created_at FROM videos
+WHERE created_at > now() - interval '1 hour';
@@ -0,0 +1,6 @@
+-- entries the relay gave up on after OUTBOX_MAX_ATTEMPTS; they stay for
+-- inspection and are never published again
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(available_at, id) WHERE sent_at IS NULL AND dead_at IS NULL;
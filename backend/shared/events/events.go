@@ -0,0 +1,105 @@
+// Package events defines the job lifecycle events published to the
+// video.transcode.events topic. The wire format is documented in
+// job-event.v1.schema.json next to this file; bump SchemaVersion (and add a
+// new schema file) for any change that is not purely additive.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"video-encoding/shared/store"
+	"video-encoding/shared/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Topic         = "video.transcode.events"
+	SchemaVersion = 1
+
+	// Kafka headers set on every message, so consumers can route without
+	// decoding the body.
+	HeaderSchemaVersion = "schema-version"
+	HeaderEventType     = "event-type"
+)
+
+// Event types.
+const (
+	JobQueued         = "job.queued"
+	JobStarted        = "job.started"
+	JobProgress       = "job.progress"
+	JobRenditionReady = "job.rendition_ready"
+	JobCompleted      = "job.completed"
+	JobFailed         = "job.failed"
+	JobCancelled      = "job.cancelled"
+)
+
+// Sources.
+const (
+	SourceAPI      = "api"
+	SourceProducer = "producer"
+	SourceWorker   = "worker"
+)
+
+// Event is one message on the topic. Messages are keyed by VideoID, so all
+// events of a video land on the same partition, in order.
+type Event struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	Source        string    `json:"source"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	VideoID       string    `json:"videoId"`
+	JobID         string    `json:"jobId"`
+	Job           JobState  `json:"job"`
+}
+
+// JobState is the job as seen when the event happened.
+type JobState struct {
+	Status     string            `json:"status"`
+	Progress   int               `json:"progress"`
+	Options    *types.JobOptions `json:"options,omitempty"`    // job.queued
+	Rendition  string            `json:"rendition,omitempty"`  // job.rendition_ready
+	Renditions []string          `json:"renditions,omitempty"` // renditions available so far
+	MasterKey  string            `json:"masterKey,omitempty"`  // job.completed
+	Error      string            `json:"error,omitempty"`      // job.failed, job.cancelled
+}
+
+func New(eventType, source, videoID, jobID string, job JobState) Event {
+	return Event{
+		SchemaVersion: SchemaVersion,
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		Source:        source,
+		OccurredAt:    time.Now().UTC(),
+		VideoID:       videoID,
+		JobID:         jobID,
+		Job:           job,
+	}
+}
+
+// Outbox wraps the event in an outbox entry; the producer relay publishes
+// it to Topic. Use it to write the event in the same transaction as the
+// change it describes.
+func (e Event) Outbox() (store.OutboxEntry, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return store.OutboxEntry{}, err
+	}
+	return store.OutboxEntry{
+		Kind:    store.OutboxJobEvent,
+		Key:     e.VideoID,
+		Payload: b,
+	}, nil
+}
+
+// Record writes the event to the outbox on its own.
+func Record(ctx context.Context, st store.Storage, e Event) error {
+	entry, err := e.Outbox()
+	if err != nil {
+		return err
+	}
+	return st.Outbox.Create(ctx, entry)
+}
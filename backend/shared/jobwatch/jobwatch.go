@@ -0,0 +1,129 @@
+package jobwatch
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres NOTIFY channel fed by the jobs table trigger.
+const Channel = "job_updates"
+
+// Update says "this job changed". It is a hint, not the state: subscribers
+// re-read the job from the store. An Update with an empty JobID is sent
+// after the listener reconnects and means "re-read everything".
+type Update struct {
+	JobID    string `json:"jobId"`
+	VideoID  string `json:"videoId"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"`
+}
+
+func (u Update) Resync() bool { return u.JobID == "" }
+
+// Hub holds one LISTEN connection per process and fans notifications out
+// to subscribers. Every replica runs its own Hub, so it works across
+// any number of API or producer instances.
+type Hub struct {
+	l *pq.Listener
+
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+type subscription struct {
+	jobID   string
+	videoID string
+	ch      chan Update
+}
+
+func NewHub(dsn string) (*Hub, error) {
+	l := pq.NewListener(dsn, time.Second, 30*time.Second, nil)
+	if err := l.Listen(Channel); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	return &Hub{
+		l:    l,
+		subs: make(map[*subscription]struct{}),
+	}, nil
+}
+
+// Run dispatches notifications until ctx is done.
+func (h *Hub) Run(ctx context.Context) {
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ping.C:
+			// detects dead connections; pq reconnects on its own
+			go h.l.Ping()
+		case n := <-h.l.Notify:
+			if n == nil {
+				// reconnected: anything may have been missed
+				h.dispatch(Update{})
+				continue
+			}
+			var u Update
+			if err := json.Unmarshal([]byte(n.Extra), &u); err != nil || u.JobID == "" {
+				continue
+			}
+			h.dispatch(u)
+		}
+	}
+}
+
+func (h *Hub) SubscribeJob(jobID string) (<-chan Update, func()) {
+	return h.subscribe(&subscription{jobID: jobID})
+}
+
+func (h *Hub) SubscribeVideo(videoID string) (<-chan Update, func()) {
+	return h.subscribe(&subscription{videoID: videoID})
+}
+
+func (h *Hub) Close() error {
+	return h.l.Close()
+}
+
+func (h *Hub) subscribe(s *subscription) (<-chan Update, func()) {
+	s.ch = make(chan Update, 16)
+
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, s)
+			h.mu.Unlock()
+		})
+	}
+}
+
+func (h *Hub) dispatch(u Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs {
+		if !u.Resync() && s.jobID != "" && s.jobID != u.JobID {
+			continue
+		}
+		if !u.Resync() && s.videoID != "" && s.videoID != u.VideoID {
+			continue
+		}
+		// never block the listener; a full buffer already holds an update
+		// that will make the subscriber re-read the latest state
+		select {
+		case s.ch <- u:
+		default:
+		}
+	}
+}
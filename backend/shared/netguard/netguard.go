@@ -0,0 +1,68 @@
+// Package netguard keeps outbound requests made for callers (imports,
+// webhook deliveries) away from internal services.
+package netguard
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateAddr = errors.New("destination resolves to a private address")
+
+// Dialer returns a dialer that refuses private addresses unless
+// allowPrivate is set (local dev). Every connection goes through it,
+// redirects included.
+func Dialer(timeout time.Duration, allowPrivate bool) *net.Dialer {
+	d := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		d.Control = DenyPrivate
+	}
+	return d
+}
+
+// DenyPrivate refuses connections to loopback, private and link-local
+// addresses. It runs after DNS resolution, so it also covers names
+// pointing inside.
+func DenyPrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || IsPrivate(ip) {
+		return ErrPrivateAddr
+	}
+	return nil
+}
+
+// reserved holds ranges the net.IP predicates miss: carrier-grade NAT,
+// which some clouds use for internal services, and "this network".
+var reserved = []*net.IPNet{
+	mustCIDR("100.64.0.0/10"),
+	mustCIDR("0.0.0.0/8"),
+}
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// IsPrivate reports whether ip is loopback, private, link-local (cloud
+// metadata endpoints), unspecified, multicast, carrier-grade NAT or in
+// 0.0.0.0/8.
+func IsPrivate(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return true
+	}
+	for _, n := range reserved {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,7 @@ package job
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,19 +22,360 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type LadderProfile int32
+
+const (
+	LadderProfile_LADDER_PROFILE_UNSPECIFIED LadderProfile = 0 // standard
+	LadderProfile_LADDER_PROFILE_STANDARD    LadderProfile = 1 // 480p, 720p, 1080p
+	LadderProfile_LADDER_PROFILE_MOBILE      LadderProfile = 2 // 360p, 480p, 720p
+	LadderProfile_LADDER_PROFILE_HD          LadderProfile = 3 // 720p, 1080p
+)
+
+// Enum value maps for LadderProfile.
+var (
+	LadderProfile_name = map[int32]string{
+		0: "LADDER_PROFILE_UNSPECIFIED",
+		1: "LADDER_PROFILE_STANDARD",
+		2: "LADDER_PROFILE_MOBILE",
+		3: "LADDER_PROFILE_HD",
+	}
+	LadderProfile_value = map[string]int32{
+		"LADDER_PROFILE_UNSPECIFIED": 0,
+		"LADDER_PROFILE_STANDARD":    1,
+		"LADDER_PROFILE_MOBILE":      2,
+		"LADDER_PROFILE_HD":          3,
+	}
+)
+
+func (x LadderProfile) Enum() *LadderProfile {
+	p := new(LadderProfile)
+	*p = x
+	return p
+}
+
+func (x LadderProfile) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LadderProfile) Descriptor() protoreflect.EnumDescriptor {
+	return file_job_proto_enumTypes[0].Descriptor()
+}
+
+func (LadderProfile) Type() protoreflect.EnumType {
+	return &file_job_proto_enumTypes[0]
+}
+
+func (x LadderProfile) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LadderProfile.Descriptor instead.
+func (LadderProfile) EnumDescriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{0}
+}
+
+type VideoCodec int32
+
+const (
+	VideoCodec_VIDEO_CODEC_UNSPECIFIED VideoCodec = 0 // h264
+	VideoCodec_VIDEO_CODEC_H264        VideoCodec = 1
+	VideoCodec_VIDEO_CODEC_H265        VideoCodec = 2
+)
+
+// Enum value maps for VideoCodec.
+var (
+	VideoCodec_name = map[int32]string{
+		0: "VIDEO_CODEC_UNSPECIFIED",
+		1: "VIDEO_CODEC_H264",
+		2: "VIDEO_CODEC_H265",
+	}
+	VideoCodec_value = map[string]int32{
+		"VIDEO_CODEC_UNSPECIFIED": 0,
+		"VIDEO_CODEC_H264":        1,
+		"VIDEO_CODEC_H265":        2,
+	}
+)
+
+func (x VideoCodec) Enum() *VideoCodec {
+	p := new(VideoCodec)
+	*p = x
+	return p
+}
+
+func (x VideoCodec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VideoCodec) Descriptor() protoreflect.EnumDescriptor {
+	return file_job_proto_enumTypes[1].Descriptor()
+}
+
+func (VideoCodec) Type() protoreflect.EnumType {
+	return &file_job_proto_enumTypes[1]
+}
+
+func (x VideoCodec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VideoCodec.Descriptor instead.
+func (VideoCodec) EnumDescriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{1}
+}
+
+type AudioCodec int32
+
+const (
+	AudioCodec_AUDIO_CODEC_UNSPECIFIED AudioCodec = 0 // aac
+	AudioCodec_AUDIO_CODEC_AAC         AudioCodec = 1
+	AudioCodec_AUDIO_CODEC_AC3         AudioCodec = 2
+	AudioCodec_AUDIO_CODEC_NONE        AudioCodec = 3 // drop audio
+)
+
+// Enum value maps for AudioCodec.
+var (
+	AudioCodec_name = map[int32]string{
+		0: "AUDIO_CODEC_UNSPECIFIED",
+		1: "AUDIO_CODEC_AAC",
+		2: "AUDIO_CODEC_AC3",
+		3: "AUDIO_CODEC_NONE",
+	}
+	AudioCodec_value = map[string]int32{
+		"AUDIO_CODEC_UNSPECIFIED": 0,
+		"AUDIO_CODEC_AAC":         1,
+		"AUDIO_CODEC_AC3":         2,
+		"AUDIO_CODEC_NONE":        3,
+	}
+)
+
+func (x AudioCodec) Enum() *AudioCodec {
+	p := new(AudioCodec)
+	*p = x
+	return p
+}
+
+func (x AudioCodec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AudioCodec) Descriptor() protoreflect.EnumDescriptor {
+	return file_job_proto_enumTypes[2].Descriptor()
+}
+
+func (AudioCodec) Type() protoreflect.EnumType {
+	return &file_job_proto_enumTypes[2]
+}
+
+func (x AudioCodec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AudioCodec.Descriptor instead.
+func (AudioCodec) EnumDescriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{2}
+}
+
+type JobPriority int32
+
+const (
+	JobPriority_JOB_PRIORITY_UNSPECIFIED JobPriority = 0 // normal
+	JobPriority_JOB_PRIORITY_LOW         JobPriority = 1
+	JobPriority_JOB_PRIORITY_NORMAL      JobPriority = 2
+	JobPriority_JOB_PRIORITY_HIGH        JobPriority = 3
+)
+
+// Enum value maps for JobPriority.
+var (
+	JobPriority_name = map[int32]string{
+		0: "JOB_PRIORITY_UNSPECIFIED",
+		1: "JOB_PRIORITY_LOW",
+		2: "JOB_PRIORITY_NORMAL",
+		3: "JOB_PRIORITY_HIGH",
+	}
+	JobPriority_value = map[string]int32{
+		"JOB_PRIORITY_UNSPECIFIED": 0,
+		"JOB_PRIORITY_LOW":         1,
+		"JOB_PRIORITY_NORMAL":      2,
+		"JOB_PRIORITY_HIGH":        3,
+	}
+)
+
+func (x JobPriority) Enum() *JobPriority {
+	p := new(JobPriority)
+	*p = x
+	return p
+}
+
+func (x JobPriority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobPriority) Descriptor() protoreflect.EnumDescriptor {
+	return file_job_proto_enumTypes[3].Descriptor()
+}
+
+func (JobPriority) Type() protoreflect.EnumType {
+	return &file_job_proto_enumTypes[3]
+}
+
+func (x JobPriority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobPriority.Descriptor instead.
+func (JobPriority) EnumDescriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{3}
+}
+
+type JobStatus int32
+
+const (
+	JobStatus_JOB_STATUS_UNSPECIFIED JobStatus = 0
+	JobStatus_JOB_STATUS_QUEUED      JobStatus = 1
+	JobStatus_JOB_STATUS_PROCESSING  JobStatus = 2
+	JobStatus_JOB_STATUS_COMPLETED   JobStatus = 3
+	JobStatus_JOB_STATUS_FAILED      JobStatus = 4
+	JobStatus_JOB_STATUS_CANCELLED   JobStatus = 5
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0: "JOB_STATUS_UNSPECIFIED",
+		1: "JOB_STATUS_QUEUED",
+		2: "JOB_STATUS_PROCESSING",
+		3: "JOB_STATUS_COMPLETED",
+		4: "JOB_STATUS_FAILED",
+		5: "JOB_STATUS_CANCELLED",
+	}
+	JobStatus_value = map[string]int32{
+		"JOB_STATUS_UNSPECIFIED": 0,
+		"JOB_STATUS_QUEUED":      1,
+		"JOB_STATUS_PROCESSING":  2,
+		"JOB_STATUS_COMPLETED":   3,
+		"JOB_STATUS_FAILED":      4,
+		"JOB_STATUS_CANCELLED":   5,
+	}
+)
+
+func (x JobStatus) Enum() *JobStatus {
+	p := new(JobStatus)
+	*p = x
+	return p
+}
+
+func (x JobStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_job_proto_enumTypes[4].Descriptor()
+}
+
+func (JobStatus) Type() protoreflect.EnumType {
+	return &file_job_proto_enumTypes[4]
+}
+
+func (x JobStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobStatus.Descriptor instead.
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{4}
+}
+
+type JobOptions struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	LadderProfile          LadderProfile          `protobuf:"varint,1,opt,name=ladder_profile,json=ladderProfile,proto3,enum=consumer.LadderProfile" json:"ladder_profile,omitempty"`
+	VideoCodec             VideoCodec             `protobuf:"varint,2,opt,name=video_codec,json=videoCodec,proto3,enum=consumer.VideoCodec" json:"video_codec,omitempty"`
+	AudioCodec             AudioCodec             `protobuf:"varint,3,opt,name=audio_codec,json=audioCodec,proto3,enum=consumer.AudioCodec" json:"audio_codec,omitempty"`
+	Priority               JobPriority            `protobuf:"varint,4,opt,name=priority,proto3,enum=consumer.JobPriority" json:"priority,omitempty"`
+	SegmentDurationSeconds uint32                 `protobuf:"varint,5,opt,name=segment_duration_seconds,json=segmentDurationSeconds,proto3" json:"segment_duration_seconds,omitempty"` // 0 = default (4s)
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *JobOptions) Reset() {
+	*x = JobOptions{}
+	mi := &file_job_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOptions) ProtoMessage() {}
+
+func (x *JobOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOptions.ProtoReflect.Descriptor instead.
+func (*JobOptions) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *JobOptions) GetLadderProfile() LadderProfile {
+	if x != nil {
+		return x.LadderProfile
+	}
+	return LadderProfile_LADDER_PROFILE_UNSPECIFIED
+}
+
+func (x *JobOptions) GetVideoCodec() VideoCodec {
+	if x != nil {
+		return x.VideoCodec
+	}
+	return VideoCodec_VIDEO_CODEC_UNSPECIFIED
+}
+
+func (x *JobOptions) GetAudioCodec() AudioCodec {
+	if x != nil {
+		return x.AudioCodec
+	}
+	return AudioCodec_AUDIO_CODEC_UNSPECIFIED
+}
+
+func (x *JobOptions) GetPriority() JobPriority {
+	if x != nil {
+		return x.Priority
+	}
+	return JobPriority_JOB_PRIORITY_UNSPECIFIED
+}
+
+func (x *JobOptions) GetSegmentDurationSeconds() uint32 {
+	if x != nil {
+		return x.SegmentDurationSeconds
+	}
+	return 0
+}
+
 type EnqueueTranscodeJobRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	VideoId       string                 `protobuf:"bytes,2,opt,name=video_id,json=videoId,proto3" json:"video_id,omitempty"`
-	InputKey      string                 `protobuf:"bytes,3,opt,name=input_key,json=inputKey,proto3" json:"input_key,omitempty"`
-	Pipeline      string                 `protobuf:"bytes,4,opt,name=pipeline,proto3" json:"pipeline,omitempty"` // "hls"
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	JobId    string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // optional, generated when empty
+	VideoId  string                 `protobuf:"bytes,2,opt,name=video_id,json=videoId,proto3" json:"video_id,omitempty"`
+	InputKey string                 `protobuf:"bytes,3,opt,name=input_key,json=inputKey,proto3" json:"input_key,omitempty"` // optional, defaults to the video's input key
+	// Deprecated: Marked as deprecated in job.proto.
+	Pipeline      string      `protobuf:"bytes,4,opt,name=pipeline,proto3" json:"pipeline,omitempty"` // "hls", use options instead
+	Options       *JobOptions `protobuf:"bytes,5,opt,name=options,proto3" json:"options,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EnqueueTranscodeJobRequest) Reset() {
 	*x = EnqueueTranscodeJobRequest{}
-	mi := &file_job_proto_msgTypes[0]
+	mi := &file_job_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -45,7 +387,7 @@ func (x *EnqueueTranscodeJobRequest) String() string {
 func (*EnqueueTranscodeJobRequest) ProtoMessage() {}
 
 func (x *EnqueueTranscodeJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_job_proto_msgTypes[0]
+	mi := &file_job_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -58,7 +400,7 @@ func (x *EnqueueTranscodeJobRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnqueueTranscodeJobRequest.ProtoReflect.Descriptor instead.
 func (*EnqueueTranscodeJobRequest) Descriptor() ([]byte, []int) {
-	return file_job_proto_rawDescGZIP(), []int{0}
+	return file_job_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *EnqueueTranscodeJobRequest) GetJobId() string {
@@ -82,6 +424,7 @@ func (x *EnqueueTranscodeJobRequest) GetInputKey() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in job.proto.
 func (x *EnqueueTranscodeJobRequest) GetPipeline() string {
 	if x != nil {
 		return x.Pipeline
@@ -89,17 +432,25 @@ func (x *EnqueueTranscodeJobRequest) GetPipeline() string {
 	return ""
 }
 
+func (x *EnqueueTranscodeJobRequest) GetOptions() *JobOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
 type EnqueueTranscodeJobResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Accepted      bool                   `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	JobId         string                 `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EnqueueTranscodeJobResponse) Reset() {
 	*x = EnqueueTranscodeJobResponse{}
-	mi := &file_job_proto_msgTypes[1]
+	mi := &file_job_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -111,7 +462,7 @@ func (x *EnqueueTranscodeJobResponse) String() string {
 func (*EnqueueTranscodeJobResponse) ProtoMessage() {}
 
 func (x *EnqueueTranscodeJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_job_proto_msgTypes[1]
+	mi := &file_job_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -124,7 +475,7 @@ func (x *EnqueueTranscodeJobResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EnqueueTranscodeJobResponse.ProtoReflect.Descriptor instead.
 func (*EnqueueTranscodeJobResponse) Descriptor() ([]byte, []int) {
-	return file_job_proto_rawDescGZIP(), []int{1}
+	return file_job_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *EnqueueTranscodeJobResponse) GetAccepted() bool {
@@ -141,21 +492,828 @@ func (x *EnqueueTranscodeJobResponse) GetMessage() string {
 	return ""
 }
 
-var File_job_proto protoreflect.FileDescriptor
+func (x *EnqueueTranscodeJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
 
-const file_job_proto_rawDesc = "" +
-	"\n" +
-	"\tjob.proto\x12\bconsumer\"\x87\x01\n" +
-	"\x1aEnqueueTranscodeJobRequest\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
-	"\bvideo_id\x18\x02 \x01(\tR\avideoId\x12\x1b\n" +
-	"\tinput_key\x18\x03 \x01(\tR\binputKey\x12\x1a\n" +
-	"\bpipeline\x18\x04 \x01(\tR\bpipeline\"S\n" +
-	"\x1bEnqueueTranscodeJobResponse\x12\x1a\n" +
-	"\baccepted\x18\x01 \x01(\bR\baccepted\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2x\n" +
+type BatchEnqueueTranscodeJobsRequest struct {
+	state         protoimpl.MessageState        `protogen:"open.v1"`
+	Jobs          []*EnqueueTranscodeJobRequest `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEnqueueTranscodeJobsRequest) Reset() {
+	*x = BatchEnqueueTranscodeJobsRequest{}
+	mi := &file_job_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEnqueueTranscodeJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEnqueueTranscodeJobsRequest) ProtoMessage() {}
+
+func (x *BatchEnqueueTranscodeJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEnqueueTranscodeJobsRequest.ProtoReflect.Descriptor instead.
+func (*BatchEnqueueTranscodeJobsRequest) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchEnqueueTranscodeJobsRequest) GetJobs() []*EnqueueTranscodeJobRequest {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type BatchEnqueueResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // position in the request
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Accepted      bool                   `protobuf:"varint,3,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEnqueueResult) Reset() {
+	*x = BatchEnqueueResult{}
+	mi := &file_job_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEnqueueResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEnqueueResult) ProtoMessage() {}
+
+func (x *BatchEnqueueResult) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEnqueueResult.ProtoReflect.Descriptor instead.
+func (*BatchEnqueueResult) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchEnqueueResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchEnqueueResult) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *BatchEnqueueResult) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *BatchEnqueueResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BatchEnqueueTranscodeJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchEnqueueResult  `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	AcceptedCount int32                  `protobuf:"varint,2,opt,name=accepted_count,json=acceptedCount,proto3" json:"accepted_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEnqueueTranscodeJobsResponse) Reset() {
+	*x = BatchEnqueueTranscodeJobsResponse{}
+	mi := &file_job_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEnqueueTranscodeJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEnqueueTranscodeJobsResponse) ProtoMessage() {}
+
+func (x *BatchEnqueueTranscodeJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEnqueueTranscodeJobsResponse.ProtoReflect.Descriptor instead.
+func (*BatchEnqueueTranscodeJobsResponse) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchEnqueueTranscodeJobsResponse) GetResults() []*BatchEnqueueResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchEnqueueTranscodeJobsResponse) GetAcceptedCount() int32 {
+	if x != nil {
+		return x.AcceptedCount
+	}
+	return 0
+}
+
+type CancelTranscodeJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTranscodeJobRequest) Reset() {
+	*x = CancelTranscodeJobRequest{}
+	mi := &file_job_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTranscodeJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTranscodeJobRequest) ProtoMessage() {}
+
+func (x *CancelTranscodeJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTranscodeJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelTranscodeJobRequest) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CancelTranscodeJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *CancelTranscodeJobRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelTranscodeJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cancelled     bool                   `protobuf:"varint,1,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	Status        JobStatus              `protobuf:"varint,2,opt,name=status,proto3,enum=consumer.JobStatus" json:"status,omitempty"` // status after the call
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTranscodeJobResponse) Reset() {
+	*x = CancelTranscodeJobResponse{}
+	mi := &file_job_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTranscodeJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTranscodeJobResponse) ProtoMessage() {}
+
+func (x *CancelTranscodeJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTranscodeJobResponse.ProtoReflect.Descriptor instead.
+func (*CancelTranscodeJobResponse) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelTranscodeJobResponse) GetCancelled() bool {
+	if x != nil {
+		return x.Cancelled
+	}
+	return false
+}
+
+func (x *CancelTranscodeJobResponse) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *CancelTranscodeJobResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetTranscodeJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscodeJobRequest) Reset() {
+	*x = GetTranscodeJobRequest{}
+	mi := &file_job_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscodeJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscodeJobRequest) ProtoMessage() {}
+
+func (x *GetTranscodeJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscodeJobRequest.ProtoReflect.Descriptor instead.
+func (*GetTranscodeJobRequest) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetTranscodeJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type JobOutputs struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	MasterKey           string                 `protobuf:"bytes,1,opt,name=master_key,json=masterKey,proto3" json:"master_key,omitempty"`
+	PlaybackReady       bool                   `protobuf:"varint,2,opt,name=playback_ready,json=playbackReady,proto3" json:"playback_ready,omitempty"`
+	AvailableRenditions []string               `protobuf:"bytes,3,rep,name=available_renditions,json=availableRenditions,proto3" json:"available_renditions,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *JobOutputs) Reset() {
+	*x = JobOutputs{}
+	mi := &file_job_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobOutputs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobOutputs) ProtoMessage() {}
+
+func (x *JobOutputs) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobOutputs.ProtoReflect.Descriptor instead.
+func (*JobOutputs) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *JobOutputs) GetMasterKey() string {
+	if x != nil {
+		return x.MasterKey
+	}
+	return ""
+}
+
+func (x *JobOutputs) GetPlaybackReady() bool {
+	if x != nil {
+		return x.PlaybackReady
+	}
+	return false
+}
+
+func (x *JobOutputs) GetAvailableRenditions() []string {
+	if x != nil {
+		return x.AvailableRenditions
+	}
+	return nil
+}
+
+type TranscodeJob struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	VideoId       string                 `protobuf:"bytes,2,opt,name=video_id,json=videoId,proto3" json:"video_id,omitempty"`
+	InputKey      string                 `protobuf:"bytes,3,opt,name=input_key,json=inputKey,proto3" json:"input_key,omitempty"`
+	Status        JobStatus              `protobuf:"varint,4,opt,name=status,proto3,enum=consumer.JobStatus" json:"status,omitempty"`
+	Progress      int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Options       *JobOptions            `protobuf:"bytes,7,opt,name=options,proto3" json:"options,omitempty"`
+	Outputs       *JobOutputs            `protobuf:"bytes,8,opt,name=outputs,proto3" json:"outputs,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscodeJob) Reset() {
+	*x = TranscodeJob{}
+	mi := &file_job_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeJob) ProtoMessage() {}
+
+func (x *TranscodeJob) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeJob.ProtoReflect.Descriptor instead.
+func (*TranscodeJob) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TranscodeJob) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *TranscodeJob) GetVideoId() string {
+	if x != nil {
+		return x.VideoId
+	}
+	return ""
+}
+
+func (x *TranscodeJob) GetInputKey() string {
+	if x != nil {
+		return x.InputKey
+	}
+	return ""
+}
+
+func (x *TranscodeJob) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *TranscodeJob) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *TranscodeJob) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *TranscodeJob) GetOptions() *JobOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *TranscodeJob) GetOutputs() *JobOutputs {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *TranscodeJob) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TranscodeJob) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetTranscodeJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *TranscodeJob          `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscodeJobResponse) Reset() {
+	*x = GetTranscodeJobResponse{}
+	mi := &file_job_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscodeJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscodeJobResponse) ProtoMessage() {}
+
+func (x *GetTranscodeJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscodeJobResponse.ProtoReflect.Descriptor instead.
+func (*GetTranscodeJobResponse) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetTranscodeJobResponse) GetJob() *TranscodeJob {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type WatchJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchJobRequest) Reset() {
+	*x = WatchJobRequest{}
+	mi := &file_job_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchJobRequest) ProtoMessage() {}
+
+func (x *WatchJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchJobRequest.ProtoReflect.Descriptor instead.
+func (*WatchJobRequest) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type JobUpdate struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	JobId               string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	VideoId             string                 `protobuf:"bytes,2,opt,name=video_id,json=videoId,proto3" json:"video_id,omitempty"`
+	Status              JobStatus              `protobuf:"varint,3,opt,name=status,proto3,enum=consumer.JobStatus" json:"status,omitempty"`
+	Progress            int32                  `protobuf:"varint,4,opt,name=progress,proto3" json:"progress,omitempty"`
+	AvailableRenditions []string               `protobuf:"bytes,5,rep,name=available_renditions,json=availableRenditions,proto3" json:"available_renditions,omitempty"`
+	PlaybackReady       bool                   `protobuf:"varint,6,opt,name=playback_ready,json=playbackReady,proto3" json:"playback_ready,omitempty"`
+	MasterKey           string                 `protobuf:"bytes,7,opt,name=master_key,json=masterKey,proto3" json:"master_key,omitempty"`
+	ErrorMessage        string                 `protobuf:"bytes,8,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"` // set when the job failed or was cancelled
+	Terminal            bool                   `protobuf:"varint,9,opt,name=terminal,proto3" json:"terminal,omitempty"`                            // last message of the stream
+	UpdatedAt           *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *JobUpdate) Reset() {
+	*x = JobUpdate{}
+	mi := &file_job_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobUpdate) ProtoMessage() {}
+
+func (x *JobUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_job_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobUpdate.ProtoReflect.Descriptor instead.
+func (*JobUpdate) Descriptor() ([]byte, []int) {
+	return file_job_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *JobUpdate) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobUpdate) GetVideoId() string {
+	if x != nil {
+		return x.VideoId
+	}
+	return ""
+}
+
+func (x *JobUpdate) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *JobUpdate) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *JobUpdate) GetAvailableRenditions() []string {
+	if x != nil {
+		return x.AvailableRenditions
+	}
+	return nil
+}
+
+func (x *JobUpdate) GetPlaybackReady() bool {
+	if x != nil {
+		return x.PlaybackReady
+	}
+	return false
+}
+
+func (x *JobUpdate) GetMasterKey() string {
+	if x != nil {
+		return x.MasterKey
+	}
+	return ""
+}
+
+func (x *JobUpdate) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *JobUpdate) GetTerminal() bool {
+	if x != nil {
+		return x.Terminal
+	}
+	return false
+}
+
+func (x *JobUpdate) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_job_proto protoreflect.FileDescriptor
+
+const file_job_proto_rawDesc = "" +
+	"\n" +
+	"\tjob.proto\x12\bconsumer\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa7\x02\n" +
+	"\n" +
+	"JobOptions\x12>\n" +
+	"\x0eladder_profile\x18\x01 \x01(\x0e2\x17.consumer.LadderProfileR\rladderProfile\x125\n" +
+	"\vvideo_codec\x18\x02 \x01(\x0e2\x14.consumer.VideoCodecR\n" +
+	"videoCodec\x125\n" +
+	"\vaudio_codec\x18\x03 \x01(\x0e2\x14.consumer.AudioCodecR\n" +
+	"audioCodec\x121\n" +
+	"\bpriority\x18\x04 \x01(\x0e2\x15.consumer.JobPriorityR\bpriority\x128\n" +
+	"\x18segment_duration_seconds\x18\x05 \x01(\rR\x16segmentDurationSeconds\"\xbb\x01\n" +
+	"\x1aEnqueueTranscodeJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bvideo_id\x18\x02 \x01(\tR\avideoId\x12\x1b\n" +
+	"\tinput_key\x18\x03 \x01(\tR\binputKey\x12\x1e\n" +
+	"\bpipeline\x18\x04 \x01(\tB\x02\x18\x01R\bpipeline\x12.\n" +
+	"\aoptions\x18\x05 \x01(\v2\x14.consumer.JobOptionsR\aoptions\"j\n" +
+	"\x1bEnqueueTranscodeJobResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\bR\baccepted\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\tR\x05jobId\"\\\n" +
+	" BatchEnqueueTranscodeJobsRequest\x128\n" +
+	"\x04jobs\x18\x01 \x03(\v2$.consumer.EnqueueTranscodeJobRequestR\x04jobs\"w\n" +
+	"\x12BatchEnqueueResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x15\n" +
+	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x1a\n" +
+	"\baccepted\x18\x03 \x01(\bR\baccepted\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\x82\x01\n" +
+	"!BatchEnqueueTranscodeJobsResponse\x126\n" +
+	"\aresults\x18\x01 \x03(\v2\x1c.consumer.BatchEnqueueResultR\aresults\x12%\n" +
+	"\x0eaccepted_count\x18\x02 \x01(\x05R\racceptedCount\"J\n" +
+	"\x19CancelTranscodeJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x81\x01\n" +
+	"\x1aCancelTranscodeJobResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x01(\bR\tcancelled\x12+\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x13.consumer.JobStatusR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"/\n" +
+	"\x16GetTranscodeJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x85\x01\n" +
+	"\n" +
+	"JobOutputs\x12\x1d\n" +
+	"\n" +
+	"master_key\x18\x01 \x01(\tR\tmasterKey\x12%\n" +
+	"\x0eplayback_ready\x18\x02 \x01(\bR\rplaybackReady\x121\n" +
+	"\x14available_renditions\x18\x03 \x03(\tR\x13availableRenditions\"\xa1\x03\n" +
+	"\fTranscodeJob\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bvideo_id\x18\x02 \x01(\tR\avideoId\x12\x1b\n" +
+	"\tinput_key\x18\x03 \x01(\tR\binputKey\x12+\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x13.consumer.JobStatusR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x05 \x01(\x05R\bprogress\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x12.\n" +
+	"\aoptions\x18\a \x01(\v2\x14.consumer.JobOptionsR\aoptions\x12.\n" +
+	"\aoutputs\x18\b \x01(\v2\x14.consumer.JobOutputsR\aoutputs\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"C\n" +
+	"\x17GetTranscodeJobResponse\x12(\n" +
+	"\x03job\x18\x01 \x01(\v2\x16.consumer.TranscodeJobR\x03job\"(\n" +
+	"\x0fWatchJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xfb\x02\n" +
+	"\tJobUpdate\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x19\n" +
+	"\bvideo_id\x18\x02 \x01(\tR\avideoId\x12+\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x13.consumer.JobStatusR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x04 \x01(\x05R\bprogress\x121\n" +
+	"\x14available_renditions\x18\x05 \x03(\tR\x13availableRenditions\x12%\n" +
+	"\x0eplayback_ready\x18\x06 \x01(\bR\rplaybackReady\x12\x1d\n" +
+	"\n" +
+	"master_key\x18\a \x01(\tR\tmasterKey\x12#\n" +
+	"\rerror_message\x18\b \x01(\tR\ferrorMessage\x12\x1a\n" +
+	"\bterminal\x18\t \x01(\bR\bterminal\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt*~\n" +
+	"\rLadderProfile\x12\x1e\n" +
+	"\x1aLADDER_PROFILE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17LADDER_PROFILE_STANDARD\x10\x01\x12\x19\n" +
+	"\x15LADDER_PROFILE_MOBILE\x10\x02\x12\x15\n" +
+	"\x11LADDER_PROFILE_HD\x10\x03*U\n" +
+	"\n" +
+	"VideoCodec\x12\x1b\n" +
+	"\x17VIDEO_CODEC_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10VIDEO_CODEC_H264\x10\x01\x12\x14\n" +
+	"\x10VIDEO_CODEC_H265\x10\x02*i\n" +
+	"\n" +
+	"AudioCodec\x12\x1b\n" +
+	"\x17AUDIO_CODEC_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fAUDIO_CODEC_AAC\x10\x01\x12\x13\n" +
+	"\x0fAUDIO_CODEC_AC3\x10\x02\x12\x14\n" +
+	"\x10AUDIO_CODEC_NONE\x10\x03*q\n" +
+	"\vJobPriority\x12\x1c\n" +
+	"\x18JOB_PRIORITY_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10JOB_PRIORITY_LOW\x10\x01\x12\x17\n" +
+	"\x13JOB_PRIORITY_NORMAL\x10\x02\x12\x15\n" +
+	"\x11JOB_PRIORITY_HIGH\x10\x03*\xa4\x01\n" +
+	"\tJobStatus\x12\x1a\n" +
+	"\x16JOB_STATUS_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11JOB_STATUS_QUEUED\x10\x01\x12\x19\n" +
+	"\x15JOB_STATUS_PROCESSING\x10\x02\x12\x18\n" +
+	"\x14JOB_STATUS_COMPLETED\x10\x03\x12\x15\n" +
+	"\x11JOB_STATUS_FAILED\x10\x04\x12\x18\n" +
+	"\x14JOB_STATUS_CANCELLED\x10\x052\xe5\x03\n" +
 	"\x12JobProducerService\x12b\n" +
-	"\x13EnqueueTranscodeJob\x12$.consumer.EnqueueTranscodeJobRequest\x1a%.consumer.EnqueueTranscodeJobResponseB\x16Z\x14shared/proto/job;jobb\x06proto3"
+	"\x13EnqueueTranscodeJob\x12$.consumer.EnqueueTranscodeJobRequest\x1a%.consumer.EnqueueTranscodeJobResponse\x12t\n" +
+	"\x19BatchEnqueueTranscodeJobs\x12*.consumer.BatchEnqueueTranscodeJobsRequest\x1a+.consumer.BatchEnqueueTranscodeJobsResponse\x12_\n" +
+	"\x12CancelTranscodeJob\x12#.consumer.CancelTranscodeJobRequest\x1a$.consumer.CancelTranscodeJobResponse\x12V\n" +
+	"\x0fGetTranscodeJob\x12 .consumer.GetTranscodeJobRequest\x1a!.consumer.GetTranscodeJobResponse\x12<\n" +
+	"\bWatchJob\x12\x19.consumer.WatchJobRequest\x1a\x13.consumer.JobUpdate0\x01B\x16Z\x14shared/proto/job;jobb\x06proto3"
 
 var (
 	file_job_proto_rawDescOnce sync.Once
@@ -169,19 +1327,62 @@ func file_job_proto_rawDescGZIP() []byte {
 	return file_job_proto_rawDescData
 }
 
-var file_job_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_job_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_job_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_job_proto_goTypes = []any{
-	(*EnqueueTranscodeJobRequest)(nil),  // 0: consumer.EnqueueTranscodeJobRequest
-	(*EnqueueTranscodeJobResponse)(nil), // 1: consumer.EnqueueTranscodeJobResponse
+	(LadderProfile)(0),                        // 0: consumer.LadderProfile
+	(VideoCodec)(0),                           // 1: consumer.VideoCodec
+	(AudioCodec)(0),                           // 2: consumer.AudioCodec
+	(JobPriority)(0),                          // 3: consumer.JobPriority
+	(JobStatus)(0),                            // 4: consumer.JobStatus
+	(*JobOptions)(nil),                        // 5: consumer.JobOptions
+	(*EnqueueTranscodeJobRequest)(nil),        // 6: consumer.EnqueueTranscodeJobRequest
+	(*EnqueueTranscodeJobResponse)(nil),       // 7: consumer.EnqueueTranscodeJobResponse
+	(*BatchEnqueueTranscodeJobsRequest)(nil),  // 8: consumer.BatchEnqueueTranscodeJobsRequest
+	(*BatchEnqueueResult)(nil),                // 9: consumer.BatchEnqueueResult
+	(*BatchEnqueueTranscodeJobsResponse)(nil), // 10: consumer.BatchEnqueueTranscodeJobsResponse
+	(*CancelTranscodeJobRequest)(nil),         // 11: consumer.CancelTranscodeJobRequest
+	(*CancelTranscodeJobResponse)(nil),        // 12: consumer.CancelTranscodeJobResponse
+	(*GetTranscodeJobRequest)(nil),            // 13: consumer.GetTranscodeJobRequest
+	(*JobOutputs)(nil),                        // 14: consumer.JobOutputs
+	(*TranscodeJob)(nil),                      // 15: consumer.TranscodeJob
+	(*GetTranscodeJobResponse)(nil),           // 16: consumer.GetTranscodeJobResponse
+	(*WatchJobRequest)(nil),                   // 17: consumer.WatchJobRequest
+	(*JobUpdate)(nil),                         // 18: consumer.JobUpdate
+	(*timestamppb.Timestamp)(nil),             // 19: google.protobuf.Timestamp
 }
 var file_job_proto_depIdxs = []int32{
-	0, // 0: consumer.JobProducerService.EnqueueTranscodeJob:input_type -> consumer.EnqueueTranscodeJobRequest
-	1, // 1: consumer.JobProducerService.EnqueueTranscodeJob:output_type -> consumer.EnqueueTranscodeJobResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: consumer.JobOptions.ladder_profile:type_name -> consumer.LadderProfile
+	1,  // 1: consumer.JobOptions.video_codec:type_name -> consumer.VideoCodec
+	2,  // 2: consumer.JobOptions.audio_codec:type_name -> consumer.AudioCodec
+	3,  // 3: consumer.JobOptions.priority:type_name -> consumer.JobPriority
+	5,  // 4: consumer.EnqueueTranscodeJobRequest.options:type_name -> consumer.JobOptions
+	6,  // 5: consumer.BatchEnqueueTranscodeJobsRequest.jobs:type_name -> consumer.EnqueueTranscodeJobRequest
+	9,  // 6: consumer.BatchEnqueueTranscodeJobsResponse.results:type_name -> consumer.BatchEnqueueResult
+	4,  // 7: consumer.CancelTranscodeJobResponse.status:type_name -> consumer.JobStatus
+	4,  // 8: consumer.TranscodeJob.status:type_name -> consumer.JobStatus
+	5,  // 9: consumer.TranscodeJob.options:type_name -> consumer.JobOptions
+	14, // 10: consumer.TranscodeJob.outputs:type_name -> consumer.JobOutputs
+	19, // 11: consumer.TranscodeJob.created_at:type_name -> google.protobuf.Timestamp
+	19, // 12: consumer.TranscodeJob.updated_at:type_name -> google.protobuf.Timestamp
+	15, // 13: consumer.GetTranscodeJobResponse.job:type_name -> consumer.TranscodeJob
+	4,  // 14: consumer.JobUpdate.status:type_name -> consumer.JobStatus
+	19, // 15: consumer.JobUpdate.updated_at:type_name -> google.protobuf.Timestamp
+	6,  // 16: consumer.JobProducerService.EnqueueTranscodeJob:input_type -> consumer.EnqueueTranscodeJobRequest
+	8,  // 17: consumer.JobProducerService.BatchEnqueueTranscodeJobs:input_type -> consumer.BatchEnqueueTranscodeJobsRequest
+	11, // 18: consumer.JobProducerService.CancelTranscodeJob:input_type -> consumer.CancelTranscodeJobRequest
+	13, // 19: consumer.JobProducerService.GetTranscodeJob:input_type -> consumer.GetTranscodeJobRequest
+	17, // 20: consumer.JobProducerService.WatchJob:input_type -> consumer.WatchJobRequest
+	7,  // 21: consumer.JobProducerService.EnqueueTranscodeJob:output_type -> consumer.EnqueueTranscodeJobResponse
+	10, // 22: consumer.JobProducerService.BatchEnqueueTranscodeJobs:output_type -> consumer.BatchEnqueueTranscodeJobsResponse
+	12, // 23: consumer.JobProducerService.CancelTranscodeJob:output_type -> consumer.CancelTranscodeJobResponse
+	16, // 24: consumer.JobProducerService.GetTranscodeJob:output_type -> consumer.GetTranscodeJobResponse
+	18, // 25: consumer.JobProducerService.WatchJob:output_type -> consumer.JobUpdate
+	21, // [21:26] is the sub-list for method output_type
+	16, // [16:21] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_job_proto_init() }
@@ -194,13 +1395,14 @@ func file_job_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_job_proto_rawDesc), len(file_job_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      5,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_job_proto_goTypes,
 		DependencyIndexes: file_job_proto_depIdxs,
+		EnumInfos:         file_job_proto_enumTypes,
 		MessageInfos:      file_job_proto_msgTypes,
 	}.Build()
 	File_job_proto = out.File
@@ -19,7 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	JobProducerService_EnqueueTranscodeJob_FullMethodName = "/consumer.JobProducerService/EnqueueTranscodeJob"
+	JobProducerService_EnqueueTranscodeJob_FullMethodName       = "/consumer.JobProducerService/EnqueueTranscodeJob"
+	JobProducerService_BatchEnqueueTranscodeJobs_FullMethodName = "/consumer.JobProducerService/BatchEnqueueTranscodeJobs"
+	JobProducerService_CancelTranscodeJob_FullMethodName        = "/consumer.JobProducerService/CancelTranscodeJob"
+	JobProducerService_GetTranscodeJob_FullMethodName           = "/consumer.JobProducerService/GetTranscodeJob"
+	JobProducerService_WatchJob_FullMethodName                  = "/consumer.JobProducerService/WatchJob"
 )
 
 // JobProducerServiceClient is the client API for JobProducerService service.
@@ -27,6 +31,12 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type JobProducerServiceClient interface {
 	EnqueueTranscodeJob(ctx context.Context, in *EnqueueTranscodeJobRequest, opts ...grpc.CallOption) (*EnqueueTranscodeJobResponse, error)
+	BatchEnqueueTranscodeJobs(ctx context.Context, in *BatchEnqueueTranscodeJobsRequest, opts ...grpc.CallOption) (*BatchEnqueueTranscodeJobsResponse, error)
+	CancelTranscodeJob(ctx context.Context, in *CancelTranscodeJobRequest, opts ...grpc.CallOption) (*CancelTranscodeJobResponse, error)
+	GetTranscodeJob(ctx context.Context, in *GetTranscodeJobRequest, opts ...grpc.CallOption) (*GetTranscodeJobResponse, error)
+	// WatchJob sends the current state, then one update per change, and
+	// ends once the job reaches a terminal status.
+	WatchJob(ctx context.Context, in *WatchJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobUpdate], error)
 }
 
 type jobProducerServiceClient struct {
@@ -47,11 +57,66 @@ func (c *jobProducerServiceClient) EnqueueTranscodeJob(ctx context.Context, in *
 	return out, nil
 }
 
+func (c *jobProducerServiceClient) BatchEnqueueTranscodeJobs(ctx context.Context, in *BatchEnqueueTranscodeJobsRequest, opts ...grpc.CallOption) (*BatchEnqueueTranscodeJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchEnqueueTranscodeJobsResponse)
+	err := c.cc.Invoke(ctx, JobProducerService_BatchEnqueueTranscodeJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobProducerServiceClient) CancelTranscodeJob(ctx context.Context, in *CancelTranscodeJobRequest, opts ...grpc.CallOption) (*CancelTranscodeJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelTranscodeJobResponse)
+	err := c.cc.Invoke(ctx, JobProducerService_CancelTranscodeJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobProducerServiceClient) GetTranscodeJob(ctx context.Context, in *GetTranscodeJobRequest, opts ...grpc.CallOption) (*GetTranscodeJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTranscodeJobResponse)
+	err := c.cc.Invoke(ctx, JobProducerService_GetTranscodeJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobProducerServiceClient) WatchJob(ctx context.Context, in *WatchJobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &JobProducerService_ServiceDesc.Streams[0], JobProducerService_WatchJob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchJobRequest, JobUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JobProducerService_WatchJobClient = grpc.ServerStreamingClient[JobUpdate]
+
 // JobProducerServiceServer is the server API for JobProducerService service.
 // All implementations must embed UnimplementedJobProducerServiceServer
 // for forward compatibility.
 type JobProducerServiceServer interface {
 	EnqueueTranscodeJob(context.Context, *EnqueueTranscodeJobRequest) (*EnqueueTranscodeJobResponse, error)
+	BatchEnqueueTranscodeJobs(context.Context, *BatchEnqueueTranscodeJobsRequest) (*BatchEnqueueTranscodeJobsResponse, error)
+	CancelTranscodeJob(context.Context, *CancelTranscodeJobRequest) (*CancelTranscodeJobResponse, error)
+	GetTranscodeJob(context.Context, *GetTranscodeJobRequest) (*GetTranscodeJobResponse, error)
+	// WatchJob sends the current state, then one update per change, and
+	// ends once the job reaches a terminal status.
+	WatchJob(*WatchJobRequest, grpc.ServerStreamingServer[JobUpdate]) error
 	mustEmbedUnimplementedJobProducerServiceServer()
 }
 
@@ -65,6 +130,18 @@ type UnimplementedJobProducerServiceServer struct{}
 func (UnimplementedJobProducerServiceServer) EnqueueTranscodeJob(context.Context, *EnqueueTranscodeJobRequest) (*EnqueueTranscodeJobResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method EnqueueTranscodeJob not implemented")
 }
+func (UnimplementedJobProducerServiceServer) BatchEnqueueTranscodeJobs(context.Context, *BatchEnqueueTranscodeJobsRequest) (*BatchEnqueueTranscodeJobsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchEnqueueTranscodeJobs not implemented")
+}
+func (UnimplementedJobProducerServiceServer) CancelTranscodeJob(context.Context, *CancelTranscodeJobRequest) (*CancelTranscodeJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelTranscodeJob not implemented")
+}
+func (UnimplementedJobProducerServiceServer) GetTranscodeJob(context.Context, *GetTranscodeJobRequest) (*GetTranscodeJobResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTranscodeJob not implemented")
+}
+func (UnimplementedJobProducerServiceServer) WatchJob(*WatchJobRequest, grpc.ServerStreamingServer[JobUpdate]) error {
+	return status.Error(codes.Unimplemented, "method WatchJob not implemented")
+}
 func (UnimplementedJobProducerServiceServer) mustEmbedUnimplementedJobProducerServiceServer() {}
 func (UnimplementedJobProducerServiceServer) testEmbeddedByValue()                            {}
 
@@ -104,6 +181,71 @@ func _JobProducerService_EnqueueTranscodeJob_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobProducerService_BatchEnqueueTranscodeJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchEnqueueTranscodeJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobProducerServiceServer).BatchEnqueueTranscodeJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobProducerService_BatchEnqueueTranscodeJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobProducerServiceServer).BatchEnqueueTranscodeJobs(ctx, req.(*BatchEnqueueTranscodeJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobProducerService_CancelTranscodeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelTranscodeJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobProducerServiceServer).CancelTranscodeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobProducerService_CancelTranscodeJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobProducerServiceServer).CancelTranscodeJob(ctx, req.(*CancelTranscodeJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobProducerService_GetTranscodeJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTranscodeJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobProducerServiceServer).GetTranscodeJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JobProducerService_GetTranscodeJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobProducerServiceServer).GetTranscodeJob(ctx, req.(*GetTranscodeJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobProducerService_WatchJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchJobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobProducerServiceServer).WatchJob(m, &grpc.GenericServerStream[WatchJobRequest, JobUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JobProducerService_WatchJobServer = grpc.ServerStreamingServer[JobUpdate]
+
 // JobProducerService_ServiceDesc is the grpc.ServiceDesc for JobProducerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +257,25 @@ var JobProducerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "EnqueueTranscodeJob",
 			Handler:    _JobProducerService_EnqueueTranscodeJob_Handler,
 		},
+		{
+			MethodName: "BatchEnqueueTranscodeJobs",
+			Handler:    _JobProducerService_BatchEnqueueTranscodeJobs_Handler,
+		},
+		{
+			MethodName: "CancelTranscodeJob",
+			Handler:    _JobProducerService_CancelTranscodeJob_Handler,
+		},
+		{
+			MethodName: "GetTranscodeJob",
+			Handler:    _JobProducerService_GetTranscodeJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchJob",
+			Handler:       _JobProducerService_WatchJob_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "job.proto",
 }
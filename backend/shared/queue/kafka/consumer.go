@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"time"
+
+	ckafka "github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+type Consumer struct {
+	c *ckafka.Consumer
+}
+
+func NewConsumer(brokers, groupID string, maxPollInterval time.Duration) (*Consumer, error) {
+	cm := &ckafka.ConfigMap{
+		"bootstrap.servers":  brokers,
+		"group.id":           groupID,
+		"auto.offset.reset":  "earliest",
+		"enable.auto.commit": false, // offsets are committed on Ack
+	}
+	if maxPollInterval > 0 {
+		_ = cm.SetKey("max.poll.interval.ms", int(maxPollInterval/time.Millisecond))
+	}
+	c, err := ckafka.NewConsumer(cm)
+	if err != nil {
+		return nil, err
+	}
+	return &Consumer{c: c}, nil
+}
+
+func (co *Consumer) Close() error {
+	return co.c.Close()
+}
+
+func (co *Consumer) Subscribe(topic string) error {
+	return co.c.SubscribeTopics([]string{topic}, nil)
+}
+
+func (co *Consumer) Poll(ms int) (*ckafka.Message, error) {
+	return co.c.ReadMessage(time.Duration(ms) * time.Millisecond)
+}
+
+func (co *Consumer) Commit(m *ckafka.Message) error {
+	_, err := co.c.CommitMessage(m)
+	return err
+}
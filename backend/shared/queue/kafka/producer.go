@@ -14,10 +14,10 @@ type Producer struct {
 
 func NewProducer(brokers string) (*Producer, error) {
 	p, err := ckafka.NewProducer(&ckafka.ConfigMap{
-		"bootstrap.servers": brokers,
-		"enable.idempotence": true,
-		"acks":               "all",
-		"retries":            10,
+		"bootstrap.servers":   brokers,
+		"enable.idempotence":  true,
+		"acks":                "all",
+		"retries":             10,
 		"delivery.timeout.ms": 120000,
 	})
 	if err != nil {
@@ -36,14 +36,17 @@ func (pr *Producer) PublishJSON(ctx context.Context, topic string, key string, p
 	if err != nil {
 		return err
 	}
+	return pr.Publish(ctx, topic, key, b, nil)
+}
 
+func (pr *Producer) Publish(ctx context.Context, topic string, key string, value []byte, headers []ckafka.Header) error {
 	delivery := make(chan ckafka.Event, 1)
-	defer close(delivery)
 
-	err = pr.p.Produce(&ckafka.Message{
+	err := pr.p.Produce(&ckafka.Message{
 		TopicPartition: ckafka.TopicPartition{Topic: &topic, Partition: int32(ckafka.PartitionAny)},
 		Key:            []byte(key),
-		Value:          b,
+		Value:          value,
+		Headers:        headers,
 		Timestamp:      time.Now(),
 	}, delivery)
 	if err != nil {
@@ -58,4 +61,3 @@ func (pr *Producer) PublishJSON(ctx context.Context, topic string, key string, p
 		return m.TopicPartition.Error
 	}
 }
-
@@ -0,0 +1,195 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"video-encoding/shared/queue"
+	"video-encoding/shared/types"
+
+	ckafka "github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+const attemptHeader = "x-attempt"
+
+type Config struct {
+	Brokers      string
+	GroupID      string
+	Topic        string
+	PollInterval time.Duration
+
+	// MaxPollInterval is how long the consumer may go without polling
+	// before it is dropped from the group and its partitions go to another
+	// member. Offsets are committed on Ack, so it must exceed the time a
+	// job takes. Zero keeps librdkafka's default of 5 minutes.
+	MaxPollInterval time.Duration
+}
+
+// Queue is the Kafka backed queue.JobQueue. The producer and the consumer
+// are created on first use so a publish-only service never joins the
+// consumer group.
+type Queue struct {
+	cfg Config
+
+	mu       sync.Mutex
+	producer *Producer
+	consumer *Consumer
+	closed   bool
+}
+
+var _ queue.JobQueue = (*Queue)(nil)
+
+func NewQueue(cfg Config) *Queue {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 250 * time.Millisecond
+	}
+	return &Queue{cfg: cfg}
+}
+
+func (q *Queue) Publish(ctx context.Context, job types.TranscodeJobMessage) error {
+	return q.publish(ctx, job, 1)
+}
+
+func (q *Queue) Consume(ctx context.Context) (*queue.Delivery, error) {
+	co, err := q.getConsumer()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		m, err := co.Poll(int(q.cfg.PollInterval / time.Millisecond))
+		if err != nil {
+			var kerr ckafka.Error
+			if errors.As(err, &kerr) && kerr.Code() == ckafka.ErrTimedOut {
+				continue
+			}
+			return nil, err
+		}
+		if m == nil || len(m.Value) == 0 {
+			continue
+		}
+
+		d := &queue.Delivery{Attempt: attemptOf(m), Ref: m}
+		if err := json.Unmarshal(m.Value, &d.Job); err != nil {
+			_ = co.Commit(m)
+			return nil, fmt.Errorf("%w: %v", queue.ErrMalformed, err)
+		}
+		return d, nil
+	}
+}
+
+func (q *Queue) Ack(ctx context.Context, d *queue.Delivery) error {
+	m, ok := d.Ref.(*ckafka.Message)
+	if !ok {
+		return fmt.Errorf("kafka: foreign delivery %T", d.Ref)
+	}
+	co, err := q.getConsumer()
+	if err != nil {
+		return err
+	}
+	return co.Commit(m)
+}
+
+// Nack re-publishes the job at the tail of the topic (Kafka has no per
+// message redelivery) and commits the original offset.
+func (q *Queue) Nack(ctx context.Context, d *queue.Delivery, requeue bool) error {
+	if requeue {
+		if err := q.publish(ctx, d.Job, d.Attempt+1); err != nil {
+			return err
+		}
+	}
+	return q.Ack(ctx, d)
+}
+
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+
+	if q.producer != nil {
+		q.producer.Close()
+	}
+	if q.consumer != nil {
+		return q.consumer.Close()
+	}
+	return nil
+}
+
+func (q *Queue) publish(ctx context.Context, job types.TranscodeJobMessage, attempt int) error {
+	pr, err := q.getProducer()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	headers := []ckafka.Header{{Key: attemptHeader, Value: []byte(strconv.Itoa(attempt))}}
+	return pr.Publish(ctx, q.cfg.Topic, job.JobID, b, headers)
+}
+
+func (q *Queue) getProducer() (*Producer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, queue.ErrClosed
+	}
+	if q.producer == nil {
+		p, err := NewProducer(q.cfg.Brokers)
+		if err != nil {
+			return nil, err
+		}
+		q.producer = p
+	}
+	return q.producer, nil
+}
+
+func (q *Queue) getConsumer() (*Consumer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, queue.ErrClosed
+	}
+	if q.consumer == nil {
+		c, err := NewConsumer(q.cfg.Brokers, q.cfg.GroupID, q.cfg.MaxPollInterval)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Subscribe(q.cfg.Topic); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+		q.consumer = c
+	}
+	return q.consumer, nil
+}
+
+func attemptOf(m *ckafka.Message) int {
+	for _, h := range m.Headers {
+		if h.Key == attemptHeader {
+			if n, err := strconv.Atoi(string(h.Value)); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 1
+}
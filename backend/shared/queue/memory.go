@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"context"
+	"sync"
+
+	"video-encoding/shared/types"
+)
+
+// MemoryQueue is an in-process queue. It only connects a producer and a
+// worker living in the same process, which is enough for tests; the
+// services refuse it with ErrInProcess.
+type MemoryQueue struct {
+	ch   chan *Delivery
+	done chan struct{}
+	once sync.Once
+}
+
+func NewMemoryQueue(size int) *MemoryQueue {
+	if size <= 0 {
+		size = 128
+	}
+	return &MemoryQueue{
+		ch:   make(chan *Delivery, size),
+		done: make(chan struct{}),
+	}
+}
+
+func (q *MemoryQueue) Publish(ctx context.Context, job types.TranscodeJobMessage) error {
+	return q.push(ctx, &Delivery{Job: job, Attempt: 1})
+}
+
+func (q *MemoryQueue) Consume(ctx context.Context) (*Delivery, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-q.done:
+		return nil, ErrClosed
+	case d := <-q.ch:
+		return d, nil
+	}
+}
+
+func (q *MemoryQueue) Ack(ctx context.Context, d *Delivery) error {
+	return nil
+}
+
+func (q *MemoryQueue) Nack(ctx context.Context, d *Delivery, requeue bool) error {
+	if !requeue {
+		return nil
+	}
+	return q.push(ctx, &Delivery{Job: d.Job, Attempt: d.Attempt + 1})
+}
+
+func (q *MemoryQueue) Close() error {
+	q.once.Do(func() { close(q.done) })
+	return nil
+}
+
+func (q *MemoryQueue) push(ctx context.Context, d *Delivery) error {
+	select {
+	case <-q.done:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.done:
+		return ErrClosed
+	case q.ch <- d:
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"video-encoding/shared/types"
+)
+
+// PostgresQueue stores jobs in the job_queue table and hands them out with
+// SELECT ... FOR UPDATE SKIP LOCKED, so any number of workers can share it.
+// Higher priority jobs are claimed first.
+// A claimed row is leased for Visibility; if the worker dies the lease
+// expires and the job is picked up again.
+type PostgresQueue struct {
+	db           *sql.DB
+	name         string
+	pollInterval time.Duration
+	visibility   time.Duration
+
+	done chan struct{}
+	once sync.Once
+}
+
+type PostgresOptions struct {
+	Name         string        // logical queue name, e.g. the topic
+	PollInterval time.Duration // how long to sleep when the queue is empty
+	Visibility   time.Duration // lease duration of a claimed job
+}
+
+func NewPostgresQueue(db *sql.DB, opts PostgresOptions) *PostgresQueue {
+	if opts.Name == "" {
+		opts.Name = DefaultTopic
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Visibility <= 0 {
+		opts.Visibility = 35 * time.Minute
+	}
+	return &PostgresQueue{
+		db:           db,
+		name:         opts.Name,
+		pollInterval: opts.PollInterval,
+		visibility:   opts.Visibility,
+		done:         make(chan struct{}),
+	}
+}
+
+func (q *PostgresQueue) Publish(ctx context.Context, job types.TranscodeJobMessage) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	const stmt = `
+		INSERT INTO job_queue (queue, msg_key, payload, priority)
+		VALUES ($1, $2, $3::jsonb, $4)
+	`
+	_, err = q.db.ExecContext(ctx, stmt, q.name, job.JobID, string(payload), job.Options.WithDefaults().Priority)
+	return err
+}
+
+func (q *PostgresQueue) Consume(ctx context.Context) (*Delivery, error) {
+	for {
+		d, err := q.claim(ctx)
+		if err != nil || d != nil {
+			return d, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.done:
+			return nil, ErrClosed
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+func (q *PostgresQueue) Ack(ctx context.Context, d *Delivery) error {
+	const stmt = `DELETE FROM job_queue WHERE id=$1`
+	_, err := q.db.ExecContext(ctx, stmt, d.Ref)
+	return err
+}
+
+func (q *PostgresQueue) Nack(ctx context.Context, d *Delivery, requeue bool) error {
+	if !requeue {
+		return q.Ack(ctx, d)
+	}
+
+	const stmt = `
+		UPDATE job_queue
+		SET locked_until=NULL,
+		    available_at=now()
+		WHERE id=$1
+	`
+	_, err := q.db.ExecContext(ctx, stmt, d.Ref)
+	return err
+}
+
+func (q *PostgresQueue) Close() error {
+	q.once.Do(func() { close(q.done) })
+	return nil
+}
+
+func (q *PostgresQueue) claim(ctx context.Context) (*Delivery, error) {
+	select {
+	case <-q.done:
+		return nil, ErrClosed
+	default:
+	}
+
+	const stmt = `
+		UPDATE job_queue
+		SET attempt=attempt+1,
+		    locked_until=now() + make_interval(secs => $2)
+		WHERE id = (
+			SELECT id FROM job_queue
+			WHERE queue=$1
+			  AND available_at <= now()
+			  AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY priority DESC, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, payload, attempt
+	`
+
+	var id int64
+	var payload []byte
+	var attempt int
+
+	err := q.db.QueryRowContext(ctx, stmt, q.name, q.visibility.Seconds()).Scan(&id, &payload, &attempt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	d := &Delivery{Attempt: attempt, Ref: id}
+	if err := json.Unmarshal(payload, &d.Job); err != nil {
+		// drop it, redelivering a broken payload never helps
+		_ = q.Ack(ctx, d)
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return d, nil
+}
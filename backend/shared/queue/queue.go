@@ -0,0 +1,54 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"video-encoding/shared/types"
+)
+
+// Backend names accepted by QUEUE_BACKEND.
+const (
+	BackendKafka    = "kafka"
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// DefaultTopic is the Kafka topic, or postgres queue name, jobs go
+// through when TOPIC is unset. Producer and workers must agree on it.
+const DefaultTopic = "video.transcode.jobs"
+
+var (
+	ErrClosed    = errors.New("queue closed")
+	ErrMalformed = errors.New("malformed job message")
+
+	// ErrInProcess is returned by services asked to use the memory backend:
+	// the producer and the workers run as separate processes, so every
+	// job published to it would be lost.
+	ErrInProcess = errors.New("QUEUE_BACKEND=memory only connects a producer and a worker in one process (tests); use kafka or postgres")
+)
+
+// Delivery is one job handed to a consumer. Every delivery must be
+// acknowledged with Ack or Nack, otherwise the backend will redeliver it.
+type Delivery struct {
+	Job     types.TranscodeJobMessage
+	Attempt int
+
+	// Ref is the backend specific handle (kafka message, row id, ...).
+	Ref any
+}
+
+// JobQueue is implemented by every transport that can carry transcode jobs
+// from the producer to the workers.
+type JobQueue interface {
+	Publish(ctx context.Context, job types.TranscodeJobMessage) error
+
+	// Consume blocks until a job is available or ctx is done.
+	Consume(ctx context.Context) (*Delivery, error)
+
+	Ack(ctx context.Context, d *Delivery) error
+	// Nack gives the job back. With requeue=false the job is dropped.
+	Nack(ctx context.Context, d *Delivery, requeue bool) error
+
+	Close() error
+}
@@ -37,3 +37,7 @@ func Fail(w http.ResponseWriter, status int, code, details string) {
 		Error:   &APIError{Code: code, Details: details},
 	})
 }
+
+func Accepted(w http.ResponseWriter, message string, data any) {
+	JSON(w, http.StatusAccepted, APIResponse{Success: true, Message: message, Data: data})
+}
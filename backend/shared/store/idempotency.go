@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Begin claims rec.Key for a request. It returns nil once the caller owns
+// the key, or the stored record when the key is taken: completed, or in
+// progress elsewhere. Expired keys, and in-progress ones whose lock ran out,
+// are taken over.
+func (s *IdempotencyStore) Begin(ctx context.Context, rec IdempotencyRecord, lock, ttl time.Duration) (*IdempotencyRecord, error) {
+	const q = `
+		INSERT INTO idempotency_keys
+			(tenant_id, subject, idem_key, fingerprint, locked_until, expires_at)
+		VALUES
+			($1, $2, $3, $4, now() + make_interval(secs => $5), now() + make_interval(secs => $6))
+		ON CONFLICT (tenant_id, subject, idem_key) DO UPDATE
+		SET fingerprint=EXCLUDED.fingerprint,
+		    status='in_progress',
+		    locked_until=EXCLUDED.locked_until,
+		    response_status=NULL,
+		    response_headers=NULL,
+		    response_body=NULL,
+		    created_at=now(),
+		    expires_at=EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+		   OR (idempotency_keys.status='in_progress' AND idempotency_keys.locked_until <= now()
+		       AND idempotency_keys.fingerprint=EXCLUDED.fingerprint)
+	`
+	res, err := s.db.ExecContext(ctx, q,
+		tenantOrDefault(rec.TenantID), rec.Subject, rec.Key, rec.Fingerprint,
+		lock.Seconds(), ttl.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if aff, _ := res.RowsAffected(); aff == 1 {
+		return nil, nil
+	}
+
+	q2 := `SELECT ` + idempotencyColumns + ` FROM idempotency_keys WHERE tenant_id=$1 AND subject=$2 AND idem_key=$3`
+	out, err := scanIdempotency(s.db.QueryRowContext(ctx, q2, tenantOrDefault(rec.TenantID), rec.Subject, rec.Key))
+	if errors.Is(err, sql.ErrNoRows) {
+		// deleted meanwhile; the client can retry
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Complete stores the response of a claimed key.
+func (s *IdempotencyStore) Complete(ctx context.Context, rec IdempotencyRecord) error {
+	headers, _ := json.Marshal(rec.ResponseHeaders)
+	const q = `
+		UPDATE idempotency_keys
+		SET status='completed',
+		    response_status=$5,
+		    response_headers=$6::jsonb,
+		    response_body=$7
+		WHERE tenant_id=$1 AND subject=$2 AND idem_key=$3 AND fingerprint=$4 AND status='in_progress'
+	`
+	_, err := s.db.ExecContext(ctx, q,
+		tenantOrDefault(rec.TenantID), rec.Subject, rec.Key, rec.Fingerprint,
+		rec.ResponseStatus, string(headers), rec.ResponseBody)
+	return err
+}
+
+// Release frees a claimed key without a response, so a retry runs again.
+func (s *IdempotencyStore) Release(ctx context.Context, rec IdempotencyRecord) error {
+	const q = `
+		DELETE FROM idempotency_keys
+		WHERE tenant_id=$1 AND subject=$2 AND idem_key=$3 AND fingerprint=$4 AND status='in_progress'
+	`
+	_, err := s.db.ExecContext(ctx, q, tenantOrDefault(rec.TenantID), rec.Subject, rec.Key, rec.Fingerprint)
+	return err
+}
+
+// DeleteExpired removes up to limit expired keys and returns how many.
+func (s *IdempotencyStore) DeleteExpired(ctx context.Context, limit int) (int, error) {
+	const q = `
+		DELETE FROM idempotency_keys
+		WHERE ctid IN (
+			SELECT ctid FROM idempotency_keys
+			WHERE expires_at <= now()
+			LIMIT $1
+		)
+	`
+	res, err := s.db.ExecContext(ctx, q, limit)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ---- internal helpers ----
+
+const idempotencyColumns = `
+	tenant_id, subject, idem_key, fingerprint, status,
+	response_status, response_headers, response_body,
+	created_at, expires_at
+`
+
+func scanIdempotency(row rowScanner) (IdempotencyRecord, error) {
+	var out IdempotencyRecord
+	var status string
+	var respStatus sql.NullInt64
+	var headersRaw []byte
+
+	err := row.Scan(
+		&out.TenantID,
+		&out.Subject,
+		&out.Key,
+		&out.Fingerprint,
+		&status,
+		&respStatus,
+		&headersRaw,
+		&out.ResponseBody,
+		&out.CreatedAt,
+		&out.ExpiresAt,
+	)
+	if err != nil {
+		return IdempotencyRecord{}, err
+	}
+
+	out.Completed = status == "completed"
+	out.ResponseStatus = int(respStatus.Int64)
+	if len(headersRaw) > 0 {
+		_ = json.Unmarshal(headersRaw, &out.ResponseHeaders)
+	}
+	return out, nil
+}
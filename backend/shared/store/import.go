@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func (s *ImportStore) Create(ctx context.Context, v Video, imp Import) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := insertVideo(ctx, tx, v); err != nil {
+			return err
+		}
+
+		optsJSON, _ := json.Marshal(imp.Options)
+		const q = `
+			INSERT INTO imports (video_id, source_url, checksum_sha256, enqueue, pipeline, options)
+			VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+		`
+		_, err := tx.ExecContext(ctx, q,
+			v.ID,
+			imp.SourceURL,
+			imp.ChecksumSHA256,
+			imp.Enqueue,
+			imp.Pipeline,
+			string(optsJSON),
+		)
+		return err
+	})
+}
+
+func (s *ImportStore) Get(ctx context.Context, videoID string) (Import, error) {
+	q := `SELECT ` + importColumns + ` FROM imports WHERE video_id=$1`
+	imp, err := scanImport(s.db.QueryRowContext(ctx, q, videoID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Import{}, ErrNotFound
+		}
+		return Import{}, err
+	}
+	return imp, nil
+}
+
+func (s *ImportStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Import, error) {
+	q := `
+		UPDATE imports
+		SET status='running',
+		    attempts=attempts+1,
+		    next_attempt_at=now() + make_interval(secs => $2),
+		    updated_at=now()
+		WHERE video_id IN (
+			SELECT video_id FROM imports
+			WHERE status IN ('pending','running') AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + importColumns
+
+	rows, err := s.db.QueryContext(ctx, q, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Import
+	for rows.Next() {
+		imp, err := scanImport(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, imp)
+	}
+	return out, rows.Err()
+}
+
+func (s *ImportStore) Progress(ctx context.Context, videoID string, received int64, total *int64) error {
+	const q = `
+		UPDATE imports
+		SET bytes_received=$2,
+		    bytes_total=$3,
+		    updated_at=now()
+		WHERE video_id=$1
+	`
+	res, err := s.db.ExecContext(ctx, q, videoID, received, total)
+	if err != nil {
+		return err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *ImportStore) Complete(ctx context.Context, videoID string) error {
+	const q = `
+		UPDATE imports
+		SET status='completed',
+		    last_error=NULL,
+		    completed_at=now(),
+		    updated_at=now()
+		WHERE video_id=$1
+	`
+	_, err := s.db.ExecContext(ctx, q, videoID)
+	return err
+}
+
+func (s *ImportStore) Fail(ctx context.Context, videoID, msg string, retryAt *time.Time) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		const q = `
+			UPDATE imports
+			SET status=CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			    last_error=$2,
+			    next_attempt_at=COALESCE($3, next_attempt_at),
+			    updated_at=now()
+			WHERE video_id=$1
+		`
+		if _, err := tx.ExecContext(ctx, q, videoID, msg, retryAt); err != nil {
+			return err
+		}
+		if retryAt != nil {
+			return nil
+		}
+
+		const qVideo = `
+			UPDATE videos
+			SET status='failed',
+			    upload_state='failed',
+			    error_msg=$2,
+			    updated_at=now()
+			WHERE id=$1 AND status='pending_upload'
+		`
+		_, err := tx.ExecContext(ctx, qVideo, videoID, msg)
+		return err
+	})
+}
+
+// ---- internal helpers ----
+
+const importColumns = `
+	video_id, source_url, checksum_sha256, enqueue, pipeline, options,
+	status, bytes_received, bytes_total, attempts, last_error,
+	next_attempt_at, created_at, updated_at, completed_at
+`
+
+func scanImport(row rowScanner) (Import, error) {
+	var out Import
+	var checksum sql.NullString
+	var optsRaw []byte
+	var status string
+	var total sql.NullInt64
+	var lastErr sql.NullString
+	var completed sql.NullTime
+
+	err := row.Scan(
+		&out.VideoID,
+		&out.SourceURL,
+		&checksum,
+		&out.Enqueue,
+		&out.Pipeline,
+		&optsRaw,
+		&status,
+		&out.BytesReceived,
+		&total,
+		&out.Attempts,
+		&lastErr,
+		&out.NextAttemptAt,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+		&completed,
+	)
+	if err != nil {
+		return Import{}, err
+	}
+
+	out.Status = ImportStatus(status)
+	if checksum.Valid {
+		out.ChecksumSHA256 = &checksum.String
+	}
+	if total.Valid {
+		out.BytesTotal = &total.Int64
+	}
+	if lastErr.Valid {
+		out.LastError = &lastErr.String
+	}
+	if completed.Valid {
+		out.CompletedAt = &completed.Time
+	}
+	_ = json.Unmarshal(optsRaw, &out.Options)
+	return out, nil
+}
@@ -8,6 +8,254 @@ import (
 )
 
 func (j *JobStore) Create(ctx context.Context, job Job) error {
+	return insertJob(ctx, j.db, job)
+}
+
+func (j *JobStore) Enqueue(ctx context.Context, job Job, policy JobConflictPolicy, msgs ...OutboxEntry) ([]Job, error) {
+	var superseded []Job
+	err := withTx(ctx, j.db, func(tx *sql.Tx) error {
+		var err error
+		superseded, err = enqueueJob(ctx, tx, job, policy, msgs...)
+		return err
+	})
+	return superseded, err
+}
+
+// jobColumns matches scanJob.
+const jobColumns = `
+	id, tenant_id, video_id, input_key, pipeline, options,
+	status, error_msg,
+	output_master_key, playback_ready,
+	available_renditions, progress,
+	created_at, updated_at
+`
+
+func (j *JobStore) Get(ctx context.Context, id string) (Job, error) {
+	q := `SELECT ` + jobColumns + ` FROM jobs WHERE id=$1 AND ($2::text IS NULL OR tenant_id=$2)`
+
+	out, err := scanJob(j.db.QueryRowContext(ctx, q, id, tenantScope(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Job{}, ErrNotFound
+		}
+		return Job{}, err
+	}
+	return out, nil
+}
+
+func (j *JobStore) ListByVideo(ctx context.Context, videoID string, limit, offset int) ([]Job, int, error) {
+	const qCount = `SELECT COUNT(*) FROM jobs WHERE video_id=$1 AND ($2::text IS NULL OR tenant_id=$2)`
+	var total int
+	if err := j.db.QueryRowContext(ctx, qCount, videoID, tenantScope(ctx)).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	q := `SELECT ` + jobColumns + `
+		FROM jobs
+		WHERE video_id=$1 AND ($4::text IS NULL OR tenant_id=$4)
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := j.db.QueryContext(ctx, q, videoID, limit, offset, tenantScope(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, job)
+	}
+	return out, total, rows.Err()
+}
+
+func (j *JobStore) MarkProcessing(ctx context.Context, id string) error {
+	return j.transition(ctx, id, JobProcessing, nil)
+}
+
+func (j *JobStore) MarkFailed(ctx context.Context, id, msg string) error {
+	return j.transition(ctx, id, JobFailed, &msg)
+}
+
+func (j *JobStore) MarkCompleted(ctx context.Context, id string) error {
+	// progress goes to 100, output fields are kept as-is
+	return j.transition(ctx, id, JobCompleted, nil)
+}
+
+func (j *JobStore) Cancel(ctx context.Context, id, reason string) (JobStatus, error) {
+	var status string
+
+	err := withTx(ctx, j.db, func(tx *sql.Tx) error {
+		videoID, err := lockJobVideo(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		const q = `
+			UPDATE jobs
+			SET status='cancelled',
+			    error_msg=NULLIF($2, ''),
+			    updated_at=now()
+			WHERE id=$1 AND status IN ('queued','processing') AND ($3::text IS NULL OR tenant_id=$3)
+		`
+		res, err := tx.ExecContext(ctx, q, id, reason, tenantScope(ctx))
+		if err != nil {
+			return err
+		}
+		if aff, _ := res.RowsAffected(); aff == 0 {
+			// nothing to cancel; report where the job already is
+			const qStatus = `SELECT status FROM jobs WHERE id=$1 AND ($2::text IS NULL OR tenant_id=$2)`
+			if err := tx.QueryRowContext(ctx, qStatus, id, tenantScope(ctx)).Scan(&status); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrNotFound
+				}
+				return err
+			}
+			return nil
+		}
+		status = string(JobCancelled)
+
+		ev := JobEvent{JobID: id, VideoID: videoID, Type: jobEventCancelled, Status: JobCancelled}
+		if reason != "" {
+			ev.Error = &reason
+		}
+		if err := insertJobEvent(ctx, tx, ev); err != nil {
+			return err
+		}
+
+		if err := syncVideoStatus(ctx, tx, videoID); err != nil {
+			return err
+		}
+
+		if err := dropHeldJobs(ctx, tx, id); err != nil {
+			return err
+		}
+		return releaseHeldJob(ctx, tx, videoID)
+	})
+	return JobStatus(status), err
+}
+
+func (j *JobStore) UpdateProgress(ctx context.Context, id string, progress int, renditions []string, masterKey *string, playable bool) error {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+
+	rendsJSON, _ := json.Marshal(renditions)
+
+	const q = `
+		UPDATE jobs
+		SET progress=$2,
+		    available_renditions=$3::jsonb,
+		    output_master_key=COALESCE($4, output_master_key),
+		    playback_ready=$5,
+		    updated_at=now()
+		WHERE id=$1 AND status='processing'
+	`
+	res, err := j.db.ExecContext(ctx, q, id, progress, string(rendsJSON), masterKey, playable)
+	if err != nil {
+		return err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		// only a running job makes progress
+		if _, err := j.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+// transition moves a job to status and its video along with it, in one
+// transaction. A job that ended lets a held job of its video start.
+func (j *JobStore) transition(ctx context.Context, id string, status JobStatus, errMsg *string) error {
+	return withTx(ctx, j.db, func(tx *sql.Tx) error {
+		videoID, err := lockJobVideo(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := setJobStatus(ctx, tx, id, status, errMsg); err != nil {
+			return err
+		}
+		if err := syncVideoStatus(ctx, tx, videoID); err != nil {
+			return err
+		}
+		if status == JobProcessing {
+			return nil
+		}
+		return releaseHeldJob(ctx, tx, videoID)
+	})
+}
+
+// enqueueJob inserts a queued job, makes it the video's latest and writes
+// its timeline event and outbox entries. An active job of the video is
+// handled by policy; superseded jobs are returned.
+func enqueueJob(ctx context.Context, tx *sql.Tx, job Job, policy JobConflictPolicy, msgs ...OutboxEntry) ([]Job, error) {
+	if err := lockVideoJobs(ctx, tx, job.VideoID); err != nil {
+		return nil, err
+	}
+
+	var superseded []Job
+	hold := false
+	switch policy {
+	case JobConflictSupersede:
+		var err error
+		if superseded, err = supersedeJobs(ctx, tx, job.VideoID, job.ID); err != nil {
+			return nil, err
+		}
+	case JobConflictQueue:
+		var err error
+		if hold, err = hasActiveJob(ctx, tx, job.VideoID); err != nil {
+			return nil, err
+		}
+	default:
+		active, err := hasActiveJob(ctx, tx, job.VideoID)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			return nil, ErrJobActive
+		}
+	}
+
+	if err := insertJob(ctx, tx, job); err != nil {
+		return nil, err
+	}
+	if err := setLatestJob(ctx, tx, job.VideoID, job.ID); err != nil {
+		return nil, err
+	}
+	if err := syncVideoStatus(ctx, tx, job.VideoID); err != nil {
+		return nil, err
+	}
+	if err := insertJobEvent(ctx, tx, JobEvent{
+		JobID:   job.ID,
+		VideoID: job.VideoID,
+		Type:    jobEventQueued,
+		Status:  JobQueued,
+	}); err != nil {
+		return nil, err
+	}
+	for _, m := range msgs {
+		if err := insertOutbox(ctx, tx, m); err != nil {
+			return nil, err
+		}
+	}
+	if hold {
+		if err := holdJob(ctx, tx, job.ID); err != nil {
+			return nil, err
+		}
+	}
+	return superseded, nil
+}
+
+func insertJob(ctx context.Context, db dbtx, job Job) error {
 	if job.Status == "" {
 		job.Status = JobQueued
 	}
@@ -19,53 +267,48 @@ func (j *JobStore) Create(ctx context.Context, job Job) error {
 	}
 
 	rendsJSON, _ := json.Marshal(job.AvailableRenditions)
+	optsJSON, _ := json.Marshal(job.Options)
 
 	const q = `
 		INSERT INTO jobs
-			(id, video_id, input_key, pipeline, status, error_msg,
-			 output_master_key, playback_ready, available_renditions, progress)
+			(id, video_id, input_key, pipeline, options, status, error_msg,
+			 output_master_key, playback_ready, available_renditions, progress, tenant_id)
 		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8,$9::jsonb,$10)
+			($1,$2,$3,$4,$5::jsonb,$6,$7,$8,$9,$10::jsonb,$11,$12)
 	`
 
-	_, err := j.db.ExecContext(ctx, q,
+	_, err := db.ExecContext(ctx, q,
 		job.ID,
 		job.VideoID,
 		job.InputKey,
 		job.Pipeline,
+		string(optsJSON),
 		string(job.Status),
 		job.ErrorMsg,
 		job.OutputMasterKey,
 		job.PlaybackReady,
 		string(rendsJSON),
 		job.Progress,
+		tenantOrDefault(job.TenantID),
 	)
-	return err
+	return mapPQError(err)
 }
 
-func (j *JobStore) Get(ctx context.Context, id string) (Job, error) {
-	const q = `
-		SELECT
-			id, video_id, input_key, pipeline,
-			status, error_msg,
-			output_master_key, playback_ready,
-			available_renditions, progress,
-			created_at, updated_at
-		FROM jobs
-		WHERE id=$1
-	`
-
+func scanJob(row rowScanner) (Job, error) {
 	var out Job
 	var status string
 	var errMsg sql.NullString
 	var master sql.NullString
 	var rendsRaw []byte
+	var optsRaw []byte
 
-	err := j.db.QueryRowContext(ctx, q, id).Scan(
+	err := row.Scan(
 		&out.ID,
+		&out.TenantID,
 		&out.VideoID,
 		&out.InputKey,
 		&out.Pipeline,
+		&optsRaw,
 		&status,
 		&errMsg,
 		&master,
@@ -75,11 +318,7 @@ func (j *JobStore) Get(ctx context.Context, id string) (Job, error) {
 		&out.CreatedAt,
 		&out.UpdatedAt,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return Job{}, ErrNotFound
-		}
 		return Job{}, err
 	}
 
@@ -93,82 +332,6 @@ func (j *JobStore) Get(ctx context.Context, id string) (Job, error) {
 	}
 
 	_ = json.Unmarshal(rendsRaw, &out.AvailableRenditions)
+	_ = json.Unmarshal(optsRaw, &out.Options)
 	return out, nil
 }
-
-func (j *JobStore) MarkProcessing(ctx context.Context, id string) error {
-	return j.setStatus(ctx, id, JobProcessing, nil)
-}
-
-func (j *JobStore) MarkFailed(ctx context.Context, id, msg string) error {
-	return j.setStatus(ctx, id, JobFailed, &msg)
-}
-
-func (j *JobStore) MarkCompleted(ctx context.Context, id string) error {
-	// mark completed and progress=100, keep output fields as-is
-	const q = `
-		UPDATE jobs
-		SET status='completed',
-		    progress=100,
-		    updated_at=now()
-		WHERE id=$1
-	`
-	res, err := j.db.ExecContext(ctx, q, id)
-	if err != nil {
-		return err
-	}
-	aff, _ := res.RowsAffected()
-	if aff == 0 {
-		return ErrNotFound
-	}
-	return nil
-}
-
-func (j *JobStore) UpdateProgress(ctx context.Context, id string, progress int, renditions []string, masterKey *string, playable bool) error {
-	if progress < 0 {
-		progress = 0
-	}
-	if progress > 100 {
-		progress = 100
-	}
-
-	rendsJSON, _ := json.Marshal(renditions)
-
-	const q = `
-		UPDATE jobs
-		SET progress=$2,
-		    available_renditions=$3::jsonb,
-		    output_master_key=COALESCE($4, output_master_key),
-		    playback_ready=$5,
-		    updated_at=now()
-		WHERE id=$1
-	`
-	res, err := j.db.ExecContext(ctx, q, id, progress, string(rendsJSON), masterKey, playable)
-	if err != nil {
-		return err
-	}
-	aff, _ := res.RowsAffected()
-	if aff == 0 {
-		return ErrNotFound
-	}
-	return nil
-}
-
-func (j *JobStore) setStatus(ctx context.Context, id string, status JobStatus, errMsg *string) error {
-	const q = `
-		UPDATE jobs
-		SET status=$2,
-		    error_msg=$3,
-		    updated_at=now()
-		WHERE id=$1
-	`
-	res, err := j.db.ExecContext(ctx, q, id, string(status), errMsg)
-	if err != nil {
-		return err
-	}
-	aff, _ := res.RowsAffected()
-	if aff == 0 {
-		return ErrNotFound
-	}
-	return nil
-}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrJobActive is returned by Enqueue with JobConflictReject when the video
+// already has a queued or processing job.
+var ErrJobActive = errors.New("video has an active job")
+
+// JobConflictPolicy decides what Enqueue does when the video already has a
+// queued or processing job.
+type JobConflictPolicy string
+
+const (
+	JobConflictReject    JobConflictPolicy = "reject"    // fail with ErrJobActive
+	JobConflictSupersede JobConflictPolicy = "supersede" // cancel the active jobs first
+	JobConflictQueue     JobConflictPolicy = "queue"     // start once the active jobs end
+)
+
+func (p JobConflictPolicy) Valid() bool {
+	switch p {
+	case JobConflictReject, JobConflictSupersede, JobConflictQueue:
+		return true
+	}
+	return false
+}
+
+// lockVideoJobs serialises job changes of a video until tx ends. Take it
+// before any row lock on the video or its jobs so lock order is the same
+// everywhere.
+func lockVideoJobs(ctx context.Context, tx *sql.Tx, videoID string) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('video-jobs:' || $1))`, videoID)
+	return err
+}
+
+// lockJobVideo takes lockVideoJobs for the video of a job.
+func lockJobVideo(ctx context.Context, tx *sql.Tx, jobID string) (string, error) {
+	var videoID string
+	err := tx.QueryRowContext(ctx, `SELECT video_id FROM jobs WHERE id=$1`, jobID).Scan(&videoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return videoID, lockVideoJobs(ctx, tx, videoID)
+}
+
+func hasActiveJob(ctx context.Context, tx *sql.Tx, videoID string) (bool, error) {
+	const q = `SELECT EXISTS (SELECT 1 FROM jobs WHERE video_id=$1 AND status IN ('queued','processing'))`
+	var active bool
+	err := tx.QueryRowContext(ctx, q, videoID).Scan(&active)
+	return active, err
+}
+
+// supersedeJobs cancels the active jobs of a video for the job replacing
+// them and returns them.
+func supersedeJobs(ctx context.Context, tx *sql.Tx, videoID, byJobID string) ([]Job, error) {
+	q := `
+		UPDATE jobs
+		SET status='cancelled',
+		    error_msg=$2,
+		    updated_at=now()
+		WHERE video_id=$1 AND status IN ('queued','processing')
+		RETURNING ` + jobColumns
+	reason := "superseded by job " + byJobID
+	rows, err := tx.QueryContext(ctx, q, videoID, reason)
+	if err != nil {
+		return nil, err
+	}
+	var out []Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(out))
+	for _, j := range out {
+		ids = append(ids, j.ID)
+		if err := insertJobEvent(ctx, tx, JobEvent{
+			JobID:   j.ID,
+			VideoID: videoID,
+			Type:    jobEventCancelled,
+			Status:  JobCancelled,
+			Error:   &reason,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return out, dropHeldJobs(ctx, tx, ids...)
+}
+
+// A queued job waiting for another job of its video keeps its transcode
+// entry in the outbox with available_at 'infinity', so the relay never
+// publishes it until releaseHeldJob does.
+
+func holdJob(ctx context.Context, tx *sql.Tx, jobID string) error {
+	const q = `
+		UPDATE outbox
+		SET available_at='infinity'
+		WHERE kind=$1 AND msg_key=$2 AND sent_at IS NULL
+	`
+	_, err := tx.ExecContext(ctx, q, OutboxTranscodeJob, jobID)
+	return err
+}
+
+// dropHeldJobs deletes the held transcode entries of jobs that will never run.
+func dropHeldJobs(ctx context.Context, tx *sql.Tx, jobIDs ...string) error {
+	if len(jobIDs) == 0 {
+		return nil
+	}
+	const q = `
+		DELETE FROM outbox
+		WHERE kind=$1 AND msg_key = ANY($2) AND sent_at IS NULL AND available_at='infinity'
+	`
+	_, err := tx.ExecContext(ctx, q, OutboxTranscodeJob, pq.Array(jobIDs))
+	return err
+}
+
+// releaseHeldJob publishes the oldest held job of a video once none of its
+// other jobs is running or about to. Call it under lockVideoJobs after a
+// job of the video ended.
+func releaseHeldJob(ctx context.Context, tx *sql.Tx, videoID string) error {
+	const q = `
+		WITH held AS (
+			SELECT o.id, o.msg_key
+			FROM outbox o
+			JOIN jobs j ON j.id = o.msg_key
+			WHERE o.kind=$2 AND o.sent_at IS NULL AND o.available_at='infinity'
+			  AND j.video_id=$1 AND j.status='queued'
+		)
+		UPDATE outbox
+		SET available_at=now()
+		WHERE id = (SELECT id FROM held ORDER BY id LIMIT 1)
+		  AND NOT EXISTS (
+			SELECT 1 FROM jobs j
+			WHERE j.video_id=$1 AND j.status IN ('queued','processing')
+			  AND j.id NOT IN (SELECT msg_key FROM held)
+		  )
+	`
+	_, err := tx.ExecContext(ctx, q, videoID, OutboxTranscodeJob)
+	return err
+}
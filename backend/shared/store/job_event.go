@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+// Timeline entries the store writes itself; the names match the
+// lifecycle events in shared/events.
+const (
+	jobEventQueued    = "job.queued"
+	jobEventCancelled = "job.cancelled"
+)
+
+func (j *JobStore) AddEvent(ctx context.Context, e JobEvent) error {
+	return insertJobEvent(ctx, j.db, e)
+}
+
+func (j *JobStore) Events(ctx context.Context, jobID string) ([]JobEvent, error) {
+	const q = `
+		SELECT id, job_id, video_id, type, status, progress,
+		       attempt, worker, error, details, created_at
+		FROM job_events
+		WHERE job_id=$1
+		ORDER BY id
+	`
+	rows, err := j.db.QueryContext(ctx, q, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []JobEvent{}
+	for rows.Next() {
+		var e JobEvent
+		var status string
+		var errMsg sql.NullString
+		var details []byte
+		if err := rows.Scan(
+			&e.ID,
+			&e.JobID,
+			&e.VideoID,
+			&e.Type,
+			&status,
+			&e.Progress,
+			&e.Attempt,
+			&e.Worker,
+			&errMsg,
+			&details,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.Status = JobStatus(status)
+		if errMsg.Valid {
+			e.Error = &errMsg.String
+		}
+		_ = json.Unmarshal(details, &e.Details)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func insertJobEvent(ctx context.Context, db dbtx, e JobEvent) error {
+	details := []byte("{}")
+	if len(e.Details) > 0 {
+		b, err := json.Marshal(e.Details)
+		if err != nil {
+			return err
+		}
+		details = b
+	}
+
+	const q = `
+		INSERT INTO job_events
+			(job_id, video_id, type, status, progress, attempt, worker, error, details)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9::jsonb)
+	`
+	_, err := db.ExecContext(ctx, q,
+		e.JobID,
+		e.VideoID,
+		e.Type,
+		string(e.Status),
+		e.Progress,
+		e.Attempt,
+		e.Worker,
+		e.Error,
+		string(details),
+	)
+	return mapPQError(err)
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+const maxOutboxBackoff = 5 * time.Minute
+
+func (o *OutboxStore) Create(ctx context.Context, e OutboxEntry) error {
+	return insertOutbox(ctx, o.db, e)
+}
+
+// Relay claims the due entries in a statement of its own, pushing their
+// available_at out by lease, and publishes them after it committed, so no
+// lock or connection is held while the broker is slow. An entry whose
+// relay died mid-batch is due again once the lease is up.
+func (o *OutboxStore) Relay(ctx context.Context, limit, maxAttempts int, lease time.Duration, publish func(context.Context, OutboxEntry) error) (int, error) {
+	// SKIP LOCKED lets several relays drain the table side by side
+	const q = `
+		WITH due AS (
+			SELECT id FROM outbox
+			WHERE sent_at IS NULL AND dead_at IS NULL AND available_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox o
+		SET attempts=o.attempts+1,
+		    available_at=now() + make_interval(secs => $2)
+		FROM due
+		WHERE o.id = due.id
+		RETURNING o.id, o.kind, o.msg_key, o.payload, o.attempts, o.last_error, o.created_at
+	`
+	rows, err := o.db.QueryContext(ctx, q, limit, lease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	var batch []OutboxEntry
+	for rows.Next() {
+		var e OutboxEntry
+		var lastErr sql.NullString
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Key, &e.Payload, &e.Attempts, &lastErr, &e.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if lastErr.Valid {
+			e.LastError = &lastErr.String
+		}
+		batch = append(batch, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	// RETURNING has no order; publish jobs in the order they were created
+	sort.Slice(batch, func(i, j int) bool { return batch[i].ID < batch[j].ID })
+
+	sent := 0
+	for _, e := range batch {
+		if perr := publish(ctx, e); perr != nil {
+			if err := o.failed(ctx, e, perr, maxAttempts); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		const qSent = `
+			UPDATE outbox
+			SET last_error=NULL,
+			    sent_at=now()
+			WHERE id=$1
+		`
+		if _, err := o.db.ExecContext(ctx, qSent, e.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// failed reschedules e with backoff, or marks it dead once it used up
+// maxAttempts (0: no limit).
+func (o *OutboxStore) failed(ctx context.Context, e OutboxEntry, perr error, maxAttempts int) error {
+	if maxAttempts > 0 && e.Attempts >= maxAttempts {
+		const qDead = `
+			UPDATE outbox
+			SET last_error=$2,
+			    dead_at=now()
+			WHERE id=$1
+		`
+		_, err := o.db.ExecContext(ctx, qDead, e.ID, perr.Error())
+		return err
+	}
+
+	const qRetry = `
+		UPDATE outbox
+		SET last_error=$2,
+		    available_at=now() + make_interval(secs => $3)
+		WHERE id=$1
+	`
+	_, err := o.db.ExecContext(ctx, qRetry, e.ID, perr.Error(), outboxBackoff(e.Attempts).Seconds())
+	return err
+}
+
+// outboxBackoff doubles from 1s and caps at maxOutboxBackoff.
+func outboxBackoff(attempt int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempt && d < maxOutboxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxOutboxBackoff {
+		d = maxOutboxBackoff
+	}
+	return d
+}
+
+func insertOutbox(ctx context.Context, db dbtx, e OutboxEntry) error {
+	const q = `
+		INSERT INTO outbox (kind, msg_key, payload)
+		VALUES ($1, $2, $3::jsonb)
+	`
+	_, err := db.ExecContext(ctx, q, e.Kind, e.Key, string(e.Payload))
+	return err
+}
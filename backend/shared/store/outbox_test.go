@@ -0,0 +1,26 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{9, 256 * time.Second},
+		{10, maxOutboxBackoff},
+		{50, maxOutboxBackoff},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempt); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
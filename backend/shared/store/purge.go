@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Delete cancels the video's active jobs, removes the video (jobs and their
+// rows cascade) and schedules the purge of its S3 prefixes, in one
+// transaction. The purge starts after delay so workers have time to notice
+// the cancellation and stop uploading.
+func (v *VideoStore) Delete(ctx context.Context, id string, prefixes []string, delay time.Duration, msgs ...OutboxEntry) (Purge, error) {
+	var out Purge
+
+	err := withTx(ctx, v.db, func(tx *sql.Tx) error {
+		if err := lockVideoJobs(ctx, tx, id); err != nil {
+			return err
+		}
+
+		// lock the video so no job is enqueued for it meanwhile
+		const qLock = `SELECT owner, tenant_id FROM videos WHERE id=$1 AND ($2::text IS NULL OR tenant_id=$2) FOR UPDATE`
+		var owner sql.NullString
+		var tenantID string
+		if err := tx.QueryRowContext(ctx, qLock, id, tenantScope(ctx)).Scan(&owner, &tenantID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// the NOTIFY from this update reaches workers and /events streams
+		// before the rows disappear
+		const qCancel = `
+			UPDATE jobs
+			SET status='cancelled',
+			    error_msg='video deleted',
+			    updated_at=now()
+			WHERE video_id=$1 AND status IN ('queued','processing')
+		`
+		if _, err := tx.ExecContext(ctx, qCancel, id); err != nil {
+			return err
+		}
+		const qHeld = `
+			DELETE FROM outbox
+			WHERE kind=$1 AND sent_at IS NULL AND available_at='infinity'
+			  AND msg_key IN (SELECT id FROM jobs WHERE video_id=$2)
+		`
+		if _, err := tx.ExecContext(ctx, qHeld, OutboxTranscodeJob, id); err != nil {
+			return err
+		}
+
+		for _, m := range msgs {
+			if err := insertOutbox(ctx, tx, m); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM videos WHERE id=$1`, id); err != nil {
+			return err
+		}
+
+		// deleting the same id twice (re-created video) restarts its purge
+		const qPurge = `
+			INSERT INTO purges (video_id, prefixes, next_attempt_at, owner, tenant_id)
+			VALUES ($1, $2, now() + make_interval(secs => $3), $4, $5)
+			ON CONFLICT (video_id) DO UPDATE
+			SET prefixes=EXCLUDED.prefixes,
+			    owner=EXCLUDED.owner,
+			    tenant_id=EXCLUDED.tenant_id,
+			    status='pending',
+			    objects_deleted=0,
+			    attempts=0,
+			    last_error=NULL,
+			    next_attempt_at=EXCLUDED.next_attempt_at,
+			    completed_at=NULL,
+			    updated_at=now()
+			RETURNING ` + purgeColumns
+		p, err := scanPurge(tx.QueryRowContext(ctx, qPurge, id, pq.Array(prefixes), delay.Seconds(), owner, tenantID))
+		if err != nil {
+			return err
+		}
+		out = p
+		return nil
+	})
+	return out, err
+}
+
+func (s *PurgeStore) Get(ctx context.Context, videoID string) (Purge, error) {
+	q := `SELECT ` + purgeColumns + ` FROM purges WHERE video_id=$1 AND ($2::text IS NULL OR tenant_id=$2)`
+	p, err := scanPurge(s.db.QueryRowContext(ctx, q, videoID, tenantScope(ctx)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Purge{}, ErrNotFound
+		}
+		return Purge{}, err
+	}
+	return p, nil
+}
+
+func (s *PurgeStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]Purge, error) {
+	q := `
+		UPDATE purges
+		SET status='running',
+		    attempts=attempts+1,
+		    next_attempt_at=now() + make_interval(secs => $2),
+		    updated_at=now()
+		WHERE video_id IN (
+			SELECT video_id FROM purges
+			WHERE status IN ('pending','running') AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + purgeColumns
+	rows, err := s.db.QueryContext(ctx, q, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Purge
+	for rows.Next() {
+		p, err := scanPurge(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (s *PurgeStore) AddDeleted(ctx context.Context, videoID string, n int) error {
+	const q = `
+		UPDATE purges
+		SET objects_deleted=objects_deleted+$2,
+		    updated_at=now()
+		WHERE video_id=$1
+	`
+	_, err := s.db.ExecContext(ctx, q, videoID, n)
+	return err
+}
+
+func (s *PurgeStore) Complete(ctx context.Context, videoID string) error {
+	const q = `
+		UPDATE purges
+		SET status='completed',
+		    last_error=NULL,
+		    completed_at=now(),
+		    updated_at=now()
+		WHERE video_id=$1
+	`
+	_, err := s.db.ExecContext(ctx, q, videoID)
+	return err
+}
+
+func (s *PurgeStore) Fail(ctx context.Context, videoID, msg string, retryAt *time.Time) error {
+	const q = `
+		UPDATE purges
+		SET status=CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		    last_error=$2,
+		    next_attempt_at=COALESCE($3, next_attempt_at),
+		    updated_at=now()
+		WHERE video_id=$1
+	`
+	_, err := s.db.ExecContext(ctx, q, videoID, msg, retryAt)
+	return err
+}
+
+// ---- internal helpers ----
+
+const purgeColumns = `
+	video_id, prefixes, status, objects_deleted, attempts, last_error,
+	next_attempt_at, owner, tenant_id, created_at, updated_at, completed_at
+`
+
+func scanPurge(row rowScanner) (Purge, error) {
+	var out Purge
+	var status string
+	var lastErr sql.NullString
+	var completed sql.NullTime
+	var owner sql.NullString
+
+	err := row.Scan(
+		&out.VideoID,
+		pq.Array(&out.Prefixes),
+		&status,
+		&out.ObjectsDeleted,
+		&out.Attempts,
+		&lastErr,
+		&out.NextAttemptAt,
+		&owner,
+		&out.TenantID,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+		&completed,
+	)
+	if err != nil {
+		return Purge{}, err
+	}
+
+	out.Status = PurgeStatus(status)
+	if owner.Valid {
+		out.Owner = &owner.String
+	}
+	if lastErr.Valid {
+		out.LastError = &lastErr.String
+	}
+	if completed.Valid {
+		out.CompletedAt = &completed.Time
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Upsert creates the rendition or overwrites its measurements and status.
+func (s *RenditionStore) Upsert(ctx context.Context, r Rendition) error {
+	if r.Status == "" {
+		r.Status = RenditionPending
+	}
+
+	const q = `
+		INSERT INTO renditions
+			(job_id, name, video_codec, audio_codec, width, height,
+			 target_bitrate, measured_bitrate, segment_count, total_bytes,
+			 duration_seconds, playlist_key, status)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		ON CONFLICT (job_id, name) DO UPDATE
+		SET video_codec=EXCLUDED.video_codec,
+		    audio_codec=EXCLUDED.audio_codec,
+		    width=EXCLUDED.width,
+		    height=EXCLUDED.height,
+		    target_bitrate=EXCLUDED.target_bitrate,
+		    measured_bitrate=EXCLUDED.measured_bitrate,
+		    segment_count=EXCLUDED.segment_count,
+		    total_bytes=EXCLUDED.total_bytes,
+		    duration_seconds=EXCLUDED.duration_seconds,
+		    playlist_key=EXCLUDED.playlist_key,
+		    status=EXCLUDED.status,
+		    updated_at=now()
+	`
+	_, err := s.db.ExecContext(ctx, q,
+		r.JobID,
+		r.Name,
+		r.VideoCodec,
+		r.AudioCodec,
+		r.Width,
+		r.Height,
+		r.TargetBitrate,
+		r.MeasuredBitrate,
+		r.SegmentCount,
+		r.TotalBytes,
+		r.DurationSeconds,
+		r.PlaylistKey,
+		string(r.Status),
+	)
+	return mapPQError(err)
+}
+
+func (s *RenditionStore) ListByJob(ctx context.Context, jobID string) ([]Rendition, error) {
+	const q = `
+		SELECT job_id, name, video_codec, audio_codec, width, height,
+		       target_bitrate, measured_bitrate, segment_count, total_bytes,
+		       duration_seconds, playlist_key, status, created_at, updated_at
+		FROM renditions
+		WHERE job_id=$1
+		ORDER BY height, target_bitrate
+	`
+	rows, err := s.db.QueryContext(ctx, q, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Rendition{}
+	for rows.Next() {
+		var r Rendition
+		var status string
+		var measured sql.NullInt64
+		if err := rows.Scan(
+			&r.JobID,
+			&r.Name,
+			&r.VideoCodec,
+			&r.AudioCodec,
+			&r.Width,
+			&r.Height,
+			&r.TargetBitrate,
+			&measured,
+			&r.SegmentCount,
+			&r.TotalBytes,
+			&r.DurationSeconds,
+			&r.PlaylistKey,
+			&status,
+			&r.CreatedAt,
+			&r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		r.Status = RenditionStatus(status)
+		if measured.Valid {
+			m := int(measured.Int64)
+			r.MeasuredBitrate = &m
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *RenditionStore) FailPending(ctx context.Context, jobID string) error {
+	const q = `
+		UPDATE renditions
+		SET status='failed',
+		    updated_at=now()
+		WHERE job_id=$1 AND status='pending'
+	`
+	_, err := s.db.ExecContext(ctx, q, jobID)
+	return err
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// TransitionError reports a status change the state machine does not
+// allow from the row's current status. It matches ErrConflict with
+// errors.Is: the row has moved on.
+type TransitionError struct {
+	Entity string // "job" or "video"
+	ID     string
+	From   string
+	To     string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s %s: illegal transition %s -> %s", e.Entity, e.ID, e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool { return target == ErrConflict }
+
+// jobTransitions lists the statuses each job status may move to. A worker
+// that is handed a job again after a crash marks it processing twice.
+// Completed, failed and cancelled are final: a retry is a new job.
+var jobTransitions = map[JobStatus][]JobStatus{
+	JobQueued:     {JobProcessing, JobFailed, JobCancelled},
+	JobProcessing: {JobProcessing, JobCompleted, JobFailed, JobCancelled},
+}
+
+func (s JobStatus) CanBecome(to JobStatus) bool {
+	for _, t := range jobTransitions[s] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// jobSources lists the statuses a job may enter to from.
+func jobSources(to JobStatus) []string {
+	var out []string
+	for from, tos := range jobTransitions {
+		for _, t := range tos {
+			if t == to {
+				out = append(out, string(from))
+			}
+		}
+	}
+	return out
+}
+
+// videoTransitions lists the statuses each video status may move to. Once
+// uploaded, a video's status follows its latest job (see videoStatusFor).
+var videoTransitions = map[Status][]Status{
+	PendingUpload: {Uploaded, Failed}, // failed: the import gave up
+	Uploaded:      {Uploaded, Processing, Ready, Failed},
+	Processing:    {Uploaded, Processing, Ready, Failed},
+	Ready:         {Uploaded, Processing, Ready, Failed},
+	Failed:        {Uploaded, Processing, Ready, Failed},
+}
+
+func (s Status) CanBecome(to Status) bool {
+	for _, t := range videoTransitions[s] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// videoStatusFor is the status of a video whose latest job is in s.
+func videoStatusFor(s JobStatus) Status {
+	switch s {
+	case JobCompleted:
+		return Ready
+	case JobFailed:
+		return Failed
+	case JobCancelled:
+		return Uploaded
+	default:
+		return Processing
+	}
+}
+
+// setJobStatus moves a job to status if its current status allows it, and
+// returns its video. Call it under lockVideoJobs.
+func setJobStatus(ctx context.Context, tx *sql.Tx, id string, status JobStatus, errMsg *string) (string, error) {
+	const q = `
+		UPDATE jobs
+		SET status=$2,
+		    error_msg=$3,
+		    progress=CASE WHEN $2='completed' THEN 100 ELSE progress END,
+		    updated_at=now()
+		WHERE id=$1 AND status = ANY($4)
+		RETURNING video_id
+	`
+	var videoID string
+	err := tx.QueryRowContext(ctx, q, id, string(status), errMsg, pq.Array(jobSources(status))).Scan(&videoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		var from string
+		if err := tx.QueryRowContext(ctx, `SELECT status FROM jobs WHERE id=$1`, id).Scan(&from); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return "", ErrNotFound
+			}
+			return "", err
+		}
+		return "", &TransitionError{Entity: "job", ID: id, From: from, To: string(status)}
+	}
+	return videoID, err
+}
+
+// syncVideoStatus derives the status and error of a video from its latest
+// job. Call it under lockVideoJobs whenever a job of the video changes
+// status or becomes the latest.
+func syncVideoStatus(ctx context.Context, tx *sql.Tx, videoID string) error {
+	const q = `
+		SELECT v.status, v.error_msg, j.id, j.status, j.error_msg
+		FROM videos v
+		JOIN jobs j ON j.id = v.latest_job_id
+		WHERE v.id=$1
+	`
+	var from, jobID, jobStatus string
+	var errMsg, jobErr sql.NullString
+	err := tx.QueryRowContext(ctx, q, videoID).Scan(&from, &errMsg, &jobID, &jobStatus, &jobErr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // deleted, or no job yet
+	}
+	if err != nil {
+		return err
+	}
+
+	to := videoStatusFor(JobStatus(jobStatus))
+	var toErr *string
+	if to == Failed && jobErr.Valid {
+		toErr = &jobErr.String
+	}
+	if Status(from) == to && errMsg.Valid == (toErr != nil) && (toErr == nil || errMsg.String == *toErr) {
+		return nil // unchanged; keep updated_at, it is the video's ETag
+	}
+	if !Status(from).CanBecome(to) {
+		return &TransitionError{Entity: "video", ID: videoID, From: from, To: string(to)}
+	}
+
+	const qSet = `
+		UPDATE videos
+		SET status=$2,
+		    error_msg=$3,
+		    updated_at=now()
+		WHERE id=$1 AND status=$4
+	`
+	res, err := tx.ExecContext(ctx, qSet, videoID, string(to), toErr, from)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff == 0 {
+		return ErrConflict
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestJobCanBecome(t *testing.T) {
+	tests := []struct {
+		from, to JobStatus
+		want     bool
+	}{
+		{JobQueued, JobProcessing, true},
+		{JobQueued, JobFailed, true},
+		{JobQueued, JobCancelled, true},
+		{JobQueued, JobCompleted, false},
+		{JobQueued, JobQueued, false},
+		// a worker handed the job again after a crash
+		{JobProcessing, JobProcessing, true},
+		{JobProcessing, JobCompleted, true},
+		{JobProcessing, JobFailed, true},
+		{JobProcessing, JobCancelled, true},
+		{JobProcessing, JobQueued, false},
+		// final states: a retry is a new job
+		{JobCompleted, JobProcessing, false},
+		{JobCompleted, JobFailed, false},
+		{JobFailed, JobProcessing, false},
+		{JobFailed, JobQueued, false},
+		{JobCancelled, JobProcessing, false},
+		{JobCancelled, JobCompleted, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanBecome(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: got %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestJobSources(t *testing.T) {
+	tests := []struct {
+		to   JobStatus
+		want []string
+	}{
+		{JobQueued, nil},
+		{JobProcessing, []string{"processing", "queued"}},
+		{JobCompleted, []string{"processing"}},
+		{JobFailed, []string{"processing", "queued"}},
+		{JobCancelled, []string{"processing", "queued"}},
+	}
+	for _, tt := range tests {
+		got := jobSources(tt.to)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("jobSources(%s) = %v, want %v", tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestVideoStatusFor(t *testing.T) {
+	tests := []struct {
+		job  JobStatus
+		want Status
+	}{
+		{JobQueued, Processing},
+		{JobProcessing, Processing},
+		{JobCompleted, Ready},
+		{JobFailed, Failed},
+		// the video is back to where it was before the job
+		{JobCancelled, Uploaded},
+	}
+	for _, tt := range tests {
+		if got := videoStatusFor(tt.job); got != tt.want {
+			t.Errorf("videoStatusFor(%s) = %s, want %s", tt.job, got, tt.want)
+		}
+	}
+}
+
+func TestVideoCanBecome(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		want     bool
+	}{
+		{PendingUpload, Uploaded, true},
+		{PendingUpload, Failed, true},
+		{PendingUpload, Processing, false},
+		{PendingUpload, Ready, false},
+		{Uploaded, Processing, true},
+		{Ready, Processing, true},
+		{Failed, Uploaded, true},
+		{Ready, PendingUpload, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanBecome(tt.to); got != tt.want {
+			t.Errorf("%s -> %s: got %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -3,10 +3,15 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
-)
 
+	"video-encoding/shared/tenant"
+	"video-encoding/shared/types"
 
+	"github.com/lib/pq"
+)
 
 type JobStatus string
 
@@ -15,13 +20,16 @@ const (
 	JobProcessing JobStatus = "processing"
 	JobCompleted  JobStatus = "completed"
 	JobFailed     JobStatus = "failed"
+	JobCancelled  JobStatus = "cancelled"
 )
 
 type Job struct {
 	ID       string
+	TenantID string
 	VideoID  string
 	InputKey string
 	Pipeline string
+	Options  types.JobOptions
 
 	Status   JobStatus
 	ErrorMsg *string
@@ -35,6 +43,66 @@ type Job struct {
 	UpdatedAt time.Time
 }
 
+// JobEvent is one entry of a job's timeline.
+type JobEvent struct {
+	ID       int64
+	JobID    string
+	VideoID  string
+	Type     string // same names as the lifecycle events, e.g. "job.started"
+	Status   JobStatus
+	Progress int
+
+	Attempt int    // queue delivery attempt, 0 when not from a worker
+	Worker  string // worker that recorded it
+
+	Error   *string
+	Details map[string]any
+
+	CreatedAt time.Time
+}
+
+// VideoPatch holds the metadata fields to change; nil fields are kept.
+type VideoPatch struct {
+	Title        *string
+	Description  *string
+	ThumbnailKey *string
+}
+
+// -------------------------
+// Rendition model
+// -------------------------
+
+type RenditionStatus string
+
+const (
+	RenditionPending RenditionStatus = "pending"
+	RenditionReady   RenditionStatus = "ready"
+	RenditionFailed  RenditionStatus = "failed"
+)
+
+// Rendition is one HLS variant produced by a job.
+type Rendition struct {
+	JobID      string
+	Name       string // 480p, 720p, ...
+	VideoCodec string
+	AudioCodec string
+	Width      int
+	Height     int
+
+	TargetBitrate   int  // kbps, from the ladder
+	MeasuredBitrate *int // kbps, total bytes over playlist duration
+
+	SegmentCount    int
+	TotalBytes      int64
+	DurationSeconds float64
+	PlaylistKey     string
+
+	Status RenditionStatus
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 // -------------------------
 // Video model
 // -------------------------
@@ -42,14 +110,28 @@ type Job struct {
 type Status string
 
 const (
-	Uploaded   Status = "uploaded"
-	Processing Status = "processing"
-	Ready      Status = "ready"
-	Failed     Status = "failed"
+	PendingUpload Status = "pending_upload" // presigned, objects not verified yet
+	Uploaded      Status = "uploaded"
+	Processing    Status = "processing"
+	Ready         Status = "ready"
+	Failed        Status = "failed"
+)
+
+// UploadState tracks the input object of a video.
+type UploadState string
+
+const (
+	UploadPending    UploadState = "pending"     // single presigned PUT
+	UploadInProgress UploadState = "in_progress" // multipart upload started
+	UploadImporting  UploadState = "importing"   // fetched from a source URL
+	UploadCompleted  UploadState = "completed"
+	UploadAborted    UploadState = "aborted"
+	UploadFailed     UploadState = "failed"
 )
 
 type Video struct {
 	ID          string
+	TenantID    string
 	Title       string
 	Description string
 	Filename    string
@@ -62,43 +144,460 @@ type Video struct {
 	Status   Status
 	ErrorMsg *string
 
+	UploadState UploadState
+	// UploadID and UploadPartSize are set for multipart uploads.
+	UploadID       *string
+	UploadPartSize *int64
+
+	// SizeBytes is set when the upload is completed.
+	SizeBytes *int64
+	// DurationSeconds is set once a job has encoded the video.
+	DurationSeconds *float64
+
+	// Owner is the subject of the caller that created the video, nil for
+	// videos created without auth.
+	Owner *string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// -------------------------
+// Purge model
+// -------------------------
+
+type PurgeStatus string
+
+const (
+	PurgePending   PurgeStatus = "pending"
+	PurgeRunning   PurgeStatus = "running"
+	PurgeCompleted PurgeStatus = "completed"
+	PurgeFailed    PurgeStatus = "failed"
+)
+
+// Purge tracks the S3 cleanup of a deleted video.
+type Purge struct {
+	VideoID        string
+	Prefixes       []string
+	Status         PurgeStatus
+	ObjectsDeleted int64
+	Attempts       int
+	LastError      *string
+	NextAttemptAt  time.Time
+	Owner          *string // of the deleted video
+	TenantID       string
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// -------------------------
+// Import model
+// -------------------------
+
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// Import tracks the server-side ingest of a video from a source URL.
+type Import struct {
+	VideoID        string
+	SourceURL      string
+	ChecksumSHA256 *string // expected hex digest
+
+	Enqueue  bool // enqueue a job once the input is in place
+	Pipeline string
+	Options  types.JobOptions
+
+	Status        ImportStatus
+	BytesReceived int64
+	BytesTotal    *int64
+	Attempts      int
+	LastError     *string
+	NextAttemptAt time.Time
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// -------------------------
+// Outbox model
+// -------------------------
+
+const (
+	OutboxTranscodeJob = "transcode.job"
+	OutboxJobEvent     = "job.event"
+)
+
+type OutboxEntry struct {
+	ID      int64
+	Kind    string
+	Key     string
+	Payload []byte
+
+	Attempts  int // including the one in progress, in Relay
+	LastError *string
+	CreatedAt time.Time
+}
+
+// -------------------------
+// Webhook model
+// -------------------------
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type WebhookSubscription struct {
+	ID          string
+	URL         string
+	Secret      string
+	Description string
+	Events      []string // empty = all events
+	Active      bool
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	Payload        []byte
+
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+
+	// filled by Claim so the dispatcher needs no second lookup
+	URL    string
+	Secret string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WebhookAttempt struct {
+	DeliveryID string
+	Attempt    int
+	StatusCode *int
+	Error      *string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// -------------------------
+// Idempotency model
+// -------------------------
+
+// IdempotencyRecord is a request sent with an Idempotency-Key and, once it
+// completed, its response. Keys are scoped to a tenant and caller.
+type IdempotencyRecord struct {
+	TenantID    string
+	Subject     string
+	Key         string
+	Fingerprint string // of method, path and body
+
+	Completed       bool
+	ResponseStatus  int
+	ResponseHeaders map[string]string
+	ResponseBody    []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// -------------------------
+// Usage model
+// -------------------------
+
+// UsageScope selects whose usage is measured: a tenant, or one owner in it.
+type UsageScope struct {
+	TenantID string
+	Owner    *string
+}
+
+// Usage is what a scope consumes right now. Deleted videos stop counting
+// towards storage, but not towards the uploads of the hour.
+type Usage struct {
+	UploadsLastHour    int64
+	OldestUploadInHour *time.Time // when the hourly count drops again
+	ActiveJobs         int64      // queued or processing
+	StoredBytes        int64      // inputs and encoded renditions
+	EncodedSeconds     float64    // duration of jobs completed this month
+}
+
+// UploadLimit caps the videos a scope may create per hour.
+type UploadLimit struct {
+	Scope   UsageScope
+	PerHour int64
+}
+
+// UploadLimitError is returned by ReserveUpload when a scope has created
+// Limit.PerHour videos in the last hour. The oldest of them leaves the
+// window at Oldest+1h.
+type UploadLimitError struct {
+	Limit  UploadLimit
+	Count  int64
+	Oldest time.Time
+}
+
+func (e *UploadLimitError) Error() string {
+	return fmt.Sprintf("created %d of %d videos allowed per hour", e.Count, e.Limit.PerHour)
+}
+
 // -------------------------
 // Stores
 // -------------------------
 
-type VideoStore struct{ db *sql.DB }
-type JobStore struct{ db *sql.DB }
+// dbtx is satisfied by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type VideoStore struct{ db dbtx }
+type JobStore struct{ db dbtx }
+type RenditionStore struct{ db dbtx }
+type OutboxStore struct{ db dbtx }
+type PurgeStore struct{ db dbtx }
+type ImportStore struct{ db dbtx }
+type WebhookStore struct{ db dbtx }
+type UsageStore struct{ db dbtx }
+type IdempotencyStore struct{ db dbtx }
 
+// Storage groups the stores. The zero value is not usable; use NewStorage.
 type Storage struct {
+	db dbtx // what the stores run on: the pool, or a transaction in WithTx
+
 	Video interface {
 		Create(ctx context.Context, v Video) error
 		Get(ctx context.Context, id string) (Video, error)
-		List(ctx context.Context, limit, offset int) ([]Video, int, error)
+		// List returns a page of videos matching f and the cursor of the
+		// next page, nil on the last one.
+		List(ctx context.Context, f VideoFilter) ([]Video, *VideoCursor, error)
+		Count(ctx context.Context, f VideoFilter) (int, error)
 
+		// SetLatestJob points the video at a job and derives its status
+		// from it.
 		SetLatestJob(ctx context.Context, videoID, jobID string) error
-		MarkProcessing(ctx context.Context, id string) error
-		MarkReady(ctx context.Context, id string) error
-		MarkFailed(ctx context.Context, id, msg string) error
+		SetDuration(ctx context.Context, id string, seconds float64) error
+
+		// CompleteUpload moves a pending_upload video to uploaded with the
+		// verified size and content type and, if job is set, enqueues it in
+		// the same transaction. It returns a *TransitionError if the video
+		// is no longer pending, so only one caller ever enqueues.
+		CompleteUpload(ctx context.Context, id string, sizeBytes int64, contentType string, job *Job, msgs ...OutboxEntry) (Video, error)
+		// SetUploadState moves the upload from one state to another and
+		// returns ErrConflict if it is not in from.
+		SetUploadState(ctx context.Context, id string, from, to UploadState) error
+
+		// Update changes metadata if updated_at still equals ifUpdatedAt,
+		// otherwise it returns ErrConflict.
+		Update(ctx context.Context, id string, p VideoPatch, ifUpdatedAt time.Time) (Video, error)
+
+		// Delete cancels active jobs, deletes the video with its jobs and
+		// schedules the purge of prefixes after delay. msgs are written to
+		// the outbox in the same transaction.
+		Delete(ctx context.Context, id string, prefixes []string, delay time.Duration, msgs ...OutboxEntry) (Purge, error)
 	}
 	Job interface {
 		Create(ctx context.Context, j Job) error
+		// Enqueue inserts the job, points the video at it and writes the
+		// outbox entries that will publish it, all in one transaction.
+		// policy decides what happens to an active job of the video; the
+		// jobs it supersedes are returned.
+		Enqueue(ctx context.Context, j Job, policy JobConflictPolicy, msgs ...OutboxEntry) ([]Job, error)
 		Get(ctx context.Context, id string) (Job, error)
+		// ListByVideo returns the jobs of a video, newest first.
+		ListByVideo(ctx context.Context, videoID string, limit, offset int) ([]Job, int, error)
 
+		// AddEvent appends to the job timeline; Events reads it oldest first.
+		// Enqueue and Cancel record their own events.
+		AddEvent(ctx context.Context, e JobEvent) error
+		Events(ctx context.Context, jobID string) ([]JobEvent, error)
+
+		// MarkProcessing, MarkFailed and MarkCompleted move the job along
+		// its state machine and derive its video's status in the same
+		// transaction. A move the job's status does not allow returns a
+		// *TransitionError.
 		MarkProcessing(ctx context.Context, id string) error
 		MarkFailed(ctx context.Context, id, msg string) error
 		MarkCompleted(ctx context.Context, id string) error
+		// Cancel moves a queued or processing job to cancelled and returns
+		// the status the job ends up in.
+		Cancel(ctx context.Context, id, reason string) (JobStatus, error)
 
 		UpdateProgress(ctx context.Context, id string, progress int, renditions []string, masterKey *string, playable bool) error
 	}
+	Rendition interface {
+		Upsert(ctx context.Context, r Rendition) error
+		// ListByJob returns the renditions of a job, smallest first.
+		ListByJob(ctx context.Context, jobID string) ([]Rendition, error)
+		// FailPending marks renditions that never became ready as failed.
+		FailPending(ctx context.Context, jobID string) error
+	}
+	Outbox interface {
+		Create(ctx context.Context, e OutboxEntry) error
+		// Relay claims up to limit due entries for lease and hands each to
+		// publish. Published entries are marked sent, failed ones are
+		// rescheduled, or marked dead after maxAttempts.
+		Relay(ctx context.Context, limit, maxAttempts int, lease time.Duration, publish func(context.Context, OutboxEntry) error) (int, error)
+	}
+	Purge interface {
+		Get(ctx context.Context, videoID string) (Purge, error)
+		// Claim leases up to limit due purges and marks them running.
+		Claim(ctx context.Context, limit int, lease time.Duration) ([]Purge, error)
+		AddDeleted(ctx context.Context, videoID string, n int) error
+		Complete(ctx context.Context, videoID string) error
+		// Fail records msg; the purge is retried at retryAt, or given up
+		// when retryAt is nil.
+		Fail(ctx context.Context, videoID, msg string, retryAt *time.Time) error
+	}
+	Import interface {
+		// Create inserts the importing video and its import together.
+		Create(ctx context.Context, v Video, imp Import) error
+		Get(ctx context.Context, videoID string) (Import, error)
+		// Claim leases up to limit due imports and marks them running.
+		Claim(ctx context.Context, limit int, lease time.Duration) ([]Import, error)
+		Progress(ctx context.Context, videoID string, received int64, total *int64) error
+		Complete(ctx context.Context, videoID string) error
+		// Fail records msg; the import is retried at retryAt. When retryAt
+		// is nil it is given up and the video is marked failed.
+		Fail(ctx context.Context, videoID, msg string, retryAt *time.Time) error
+	}
+	Webhook interface {
+		CreateSubscription(ctx context.Context, s WebhookSubscription) error
+		GetSubscription(ctx context.Context, id string) (WebhookSubscription, error)
+		ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+		UpdateSubscription(ctx context.Context, s WebhookSubscription) error
+		DeleteSubscription(ctx context.Context, id string) error
+
+		// Emit fans an event out into one pending delivery per matching
+		// active subscription and returns how many were created.
+		Emit(ctx context.Context, eventID, eventType string, payload []byte) (int, error)
+		// Claim leases up to limit due deliveries for lease.
+		Claim(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+		// RecordAttempt logs one attempt and sets the delivery to status;
+		// a pending delivery is retried at next.
+		RecordAttempt(ctx context.Context, a WebhookAttempt, status DeliveryStatus, next time.Time) error
+
+		ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]WebhookDelivery, error)
+		ListAttempts(ctx context.Context, deliveryID string) ([]WebhookAttempt, error)
+	}
+	Usage interface {
+		Get(ctx context.Context, scope UsageScope, now, monthStart time.Time) (Usage, error)
+		ReserveUpload(ctx context.Context, tenantID string, owner *string, videoID string, limits []UploadLimit, now time.Time) error
+	}
+	Idempotency interface {
+		// Begin claims a key; see IdempotencyStore.Begin.
+		Begin(ctx context.Context, rec IdempotencyRecord, lock, ttl time.Duration) (*IdempotencyRecord, error)
+		Complete(ctx context.Context, rec IdempotencyRecord) error
+		Release(ctx context.Context, rec IdempotencyRecord) error
+		DeleteExpired(ctx context.Context, limit int) (int, error)
+	}
 }
 
 func NewStorage(db *sql.DB) Storage {
+	return newStorage(db)
+}
+
+func newStorage(db dbtx) Storage {
 	return Storage{
-		Video: &VideoStore{db: db},
-		Job:   &JobStore{db: db},
+		db:          db,
+		Video:       &VideoStore{db: db},
+		Job:         &JobStore{db: db},
+		Rendition:   &RenditionStore{db: db},
+		Outbox:      &OutboxStore{db: db},
+		Purge:       &PurgeStore{db: db},
+		Import:      &ImportStore{db: db},
+		Webhook:     &WebhookStore{db: db},
+		Usage:       &UsageStore{db: db},
+		Idempotency: &IdempotencyStore{db: db},
+	}
+}
+
+// WithTx runs fn with a Storage whose stores all share one transaction,
+// committed when fn returns nil and rolled back otherwise. A failed query
+// aborts a Postgres transaction, so return store errors from fn instead of
+// carrying on. Called on the Storage fn got, WithTx nests in a savepoint.
+func (s Storage) WithTx(ctx context.Context, fn func(Storage) error) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return fn(newStorage(tx))
+	})
+}
+
+// withTx runs fn in a transaction on db. When db already is one, fn runs
+// in a savepoint of it, so fn is all-or-nothing either way and the outer
+// transaction decides about the commit.
+func withTx(ctx context.Context, db dbtx, fn func(tx *sql.Tx) error) error {
+	if tx, ok := db.(*sql.Tx); ok {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT store_tx`); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			_, _ = tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT store_tx`)
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT store_tx`)
+		return err
+	}
+
+	tx, err := db.(*sql.DB).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// tenantScope is the tenant ctx is scoped to, nil for background work that
+// spans tenants. Scoped queries add "AND ($n::text IS NULL OR tenant_id=$n)",
+// so rows of other tenants look missing.
+func tenantScope(ctx context.Context) *string {
+	if id, ok := tenant.FromContext(ctx); ok {
+		return &id
+	}
+	return nil
+}
+
+// tenantOrDefault is the tenant new rows go to.
+func tenantOrDefault(id string) string {
+	if id == "" {
+		return tenant.Default
+	}
+	return id
+}
+
+// mapPQError turns constraint violations into store errors.
+func mapPQError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return ErrDuplicate
+		case "23503": // foreign_key_violation
+			return ErrNotFound
+		}
 	}
+	return err
 }
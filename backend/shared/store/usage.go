@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Get measures the usage of scope. Uploads are counted since now-1h and
+// encoded seconds since monthStart.
+func (s *UsageStore) Get(ctx context.Context, scope UsageScope, now, monthStart time.Time) (Usage, error) {
+	const q = `
+		WITH u AS (
+			SELECT created_at FROM upload_ledger
+			WHERE tenant_id = $1 AND ($2::text IS NULL OR owner = $2) AND created_at > $3
+		), v AS (
+			SELECT id, created_at, size_bytes FROM videos
+			WHERE tenant_id = $1 AND ($2::text IS NULL OR owner = $2)
+		), j AS (
+			SELECT jobs.id, jobs.status, jobs.updated_at FROM jobs
+			JOIN v ON v.id = jobs.video_id
+			WHERE jobs.tenant_id = $1
+		)
+		SELECT
+			(SELECT COUNT(*) FROM u),
+			(SELECT MIN(created_at) FROM u),
+			(SELECT COUNT(*) FROM j WHERE status IN ('queued','processing')),
+			(SELECT COALESCE(SUM(size_bytes), 0) FROM v)
+			  + (SELECT COALESCE(SUM(r.total_bytes), 0) FROM renditions r JOIN j ON j.id = r.job_id),
+			(SELECT COALESCE(SUM(d), 0) FROM (
+				SELECT MAX(r.duration_seconds) AS d
+				FROM renditions r JOIN j ON j.id = r.job_id
+				WHERE j.status = 'completed' AND j.updated_at >= $4 AND r.status = 'ready'
+				GROUP BY r.job_id
+			) encoded)
+	`
+	var out Usage
+	var oldest sql.NullTime
+	err := s.db.QueryRowContext(ctx, q, tenantOrDefault(scope.TenantID), scope.Owner, now.Add(-time.Hour), monthStart).Scan(
+		&out.UploadsLastHour,
+		&oldest,
+		&out.ActiveJobs,
+		&out.StoredBytes,
+		&out.EncodedSeconds,
+	)
+	if err != nil {
+		return Usage{}, err
+	}
+	if oldest.Valid {
+		out.OldestUploadInHour = &oldest.Time
+	}
+	return out, nil
+}
+
+// ReserveUpload records videoID as an upload of tenantID and owner, unless
+// a scope of limits already created its PerHour videos in the last hour;
+// then it returns an *UploadLimitError. Check and record happen under a
+// per-tenant lock, so concurrent uploads can't overshoot. The record stays
+// when the video is deleted, or when creating it fails afterwards.
+func (s *UsageStore) ReserveUpload(ctx context.Context, tenantID string, owner *string, videoID string, limits []UploadLimit, now time.Time) error {
+	tenantID = tenantOrDefault(tenantID)
+	since := now.Add(-time.Hour)
+
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('video-uploads:' || $1))`, tenantID); err != nil {
+			return err
+		}
+		// rows out of the window never count again
+		if _, err := tx.ExecContext(ctx, `DELETE FROM upload_ledger WHERE tenant_id = $1 AND created_at <= $2`, tenantID, since); err != nil {
+			return err
+		}
+
+		for _, l := range limits {
+			if l.PerHour <= 0 {
+				continue
+			}
+			const q = `
+				SELECT COUNT(*), MIN(created_at) FROM upload_ledger
+				WHERE tenant_id = $1 AND ($2::text IS NULL OR owner = $2) AND created_at > $3
+			`
+			var n int64
+			var oldest sql.NullTime
+			if err := tx.QueryRowContext(ctx, q, tenantID, l.Scope.Owner, since).Scan(&n, &oldest); err != nil {
+				return err
+			}
+			if n >= l.PerHour {
+				return &UploadLimitError{Limit: l, Count: n, Oldest: oldest.Time}
+			}
+		}
+
+		const q = `INSERT INTO upload_ledger (tenant_id, owner, video_id, created_at) VALUES ($1, $2, $3, $4)`
+		_, err := tx.ExecContext(ctx, q, tenantID, owner, videoID, now)
+		return err
+	})
+}
@@ -4,203 +4,176 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 )
 
-var ErrNotFound = errors.New("video not found")
+var (
+	ErrNotFound  = errors.New("video not found")
+	ErrDuplicate = errors.New("already exists")
+	ErrConflict  = errors.New("modified concurrently")
+)
 
 func (v *VideoStore) Create(ctx context.Context, video Video) error {
-	const q = `
-		INSERT INTO videos
-			(id, title, description, filename, content_type, input_key, thumbnail_key, latest_job_id, status, error_msg)
-		VALUES
-			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
-	`
-	_, err := v.db.ExecContext(
-		ctx,
-		q,
-		video.ID,
-		video.Title,
-		video.Description,
-		video.Filename,
-		video.ContentType,
-		video.InputKey,
-		video.ThumbnailKey,
-		video.LatestJobID, // can be nil
-		string(video.Status),
-		video.ErrorMsg, // can be nil
-	)
-	return err
+	return insertVideo(ctx, v.db, video)
 }
 
-func (v *VideoStore) Get(ctx context.Context, id string) (Video, error) {
-	const q = `
-		SELECT
-			id, title, description, filename, content_type, input_key,
-			thumbnail_key, latest_job_id,
-			status, error_msg,
-			created_at, updated_at
-		FROM videos
-		WHERE id = $1
-	`
-
-	var out Video
-	var latestJob sql.NullString
-	var errMsg sql.NullString
-	var status string
+// videoColumns matches scanVideo.
+const videoColumns = `
+	id, tenant_id, title, description, filename, content_type, input_key,
+	thumbnail_key, latest_job_id,
+	status, error_msg, upload_state, upload_id, upload_part_size,
+	size_bytes, duration_seconds, owner,
+	created_at, updated_at
+`
 
-	err := v.db.QueryRowContext(ctx, q, id).Scan(
-		&out.ID,
-		&out.Title,
-		&out.Description,
-		&out.Filename,
-		&out.ContentType,
-		&out.InputKey,
-		&out.ThumbnailKey,
-		&latestJob,
-		&status,
-		&errMsg,
-		&out.CreatedAt,
-		&out.UpdatedAt,
-	)
+func (v *VideoStore) Get(ctx context.Context, id string) (Video, error) {
+	q := `SELECT ` + videoColumns + ` FROM videos WHERE id = $1 AND ($2::text IS NULL OR tenant_id = $2)`
 
+	out, err := scanVideo(v.db.QueryRowContext(ctx, q, id, tenantScope(ctx)))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return Video{}, ErrNotFound
 		}
 		return Video{}, err
 	}
-
-	out.Status = Status(status)
-
-	if latestJob.Valid {
-		out.LatestJobID = &latestJob.String
-	} else {
-		out.LatestJobID = nil
-	}
-
-	if errMsg.Valid {
-		out.ErrorMsg = &errMsg.String
-	} else {
-		out.ErrorMsg = nil
-	}
-
 	return out, nil
 }
 
-func (v *VideoStore) List(ctx context.Context, limit, offset int) ([]Video, int, error) {
-	// Total count
-	const qCount = `SELECT COUNT(*) FROM videos`
-	var total int
-	if err := v.db.QueryRowContext(ctx, qCount).Scan(&total); err != nil {
-		return nil, 0, err
-	}
+func (v *VideoStore) SetLatestJob(ctx context.Context, videoID, jobID string) error {
+	return withTx(ctx, v.db, func(tx *sql.Tx) error {
+		if err := lockVideoJobs(ctx, tx, videoID); err != nil {
+			return err
+		}
+		if err := setLatestJob(ctx, tx, videoID, jobID); err != nil {
+			return err
+		}
+		return syncVideoStatus(ctx, tx, videoID)
+	})
+}
 
-	// Items
+func (v *VideoStore) SetDuration(ctx context.Context, id string, seconds float64) error {
 	const q = `
-		SELECT
-			id, title, description, filename, content_type, input_key,
-			thumbnail_key, latest_job_id,
-			status, error_msg,
-			created_at, updated_at
-		FROM videos
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		UPDATE videos
+		SET duration_seconds = $2,
+		    updated_at = now()
+		WHERE id = $1
 	`
-
-	rows, err := v.db.QueryContext(ctx, q, limit, offset)
+	res, err := v.db.ExecContext(ctx, q, id, seconds)
 	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	out := make([]Video, 0, limit)
-
-	for rows.Next() {
-		var item Video
-		var latestJob sql.NullString
-		var errMsg sql.NullString
-		var status string
-
-		if err := rows.Scan(
-			&item.ID,
-			&item.Title,
-			&item.Description,
-			&item.Filename,
-			&item.ContentType,
-			&item.InputKey,
-			&item.ThumbnailKey,
-			&latestJob,
-			&status,
-			&errMsg,
-			&item.CreatedAt,
-			&item.UpdatedAt,
-		); err != nil {
-			return nil, 0, err
+		return err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (v *VideoStore) CompleteUpload(ctx context.Context, id string, sizeBytes int64, contentType string, job *Job, msgs ...OutboxEntry) (Video, error) {
+	var out Video
+	err := withTx(ctx, v.db, func(tx *sql.Tx) error {
+		if job != nil {
+			if err := lockVideoJobs(ctx, tx, id); err != nil {
+				return err
+			}
 		}
 
-		item.Status = Status(status)
+		// compare-and-set: only a pending upload completes
+		q := `
+			UPDATE videos
+			SET status = 'uploaded',
+			    upload_state = 'completed',
+			    size_bytes = $2,
+			    content_type = $3,
+			    updated_at = now()
+			WHERE id = $1 AND status = 'pending_upload' AND ($4::text IS NULL OR tenant_id = $4)
+			RETURNING ` + videoColumns
 
-		if latestJob.Valid {
-			item.LatestJobID = &latestJob.String
+		var err error
+		out, err = scanVideo(tx.QueryRowContext(ctx, q, id, sizeBytes, contentType, tenantScope(ctx)))
+		if errors.Is(err, sql.ErrNoRows) {
+			// tell a missing video from one that is already past the upload
+			const qStatus = `SELECT status FROM videos WHERE id = $1 AND ($2::text IS NULL OR tenant_id = $2)`
+			var from string
+			if err := tx.QueryRowContext(ctx, qStatus, id, tenantScope(ctx)).Scan(&from); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrNotFound
+				}
+				return err
+			}
+			return &TransitionError{Entity: "video", ID: id, From: from, To: string(Uploaded)}
 		}
-
-		if errMsg.Valid {
-			item.ErrorMsg = &errMsg.String
+		if err != nil || job == nil {
+			return err
 		}
 
-		out = append(out, item)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
-	}
-
-	return out, total, nil
+		// nothing could run for a video that was still uploading
+		if _, err := enqueueJob(ctx, tx, *job, JobConflictReject, msgs...); err != nil {
+			return err
+		}
+		// the job moved the video on
+		out, err = scanVideo(tx.QueryRowContext(ctx, `SELECT `+videoColumns+` FROM videos WHERE id = $1`, id))
+		return err
+	})
+	return out, err
 }
 
-func (v *VideoStore) SetLatestJob(ctx context.Context, videoID, jobID string) error {
+func (v *VideoStore) SetUploadState(ctx context.Context, id string, from, to UploadState) error {
 	const q = `
 		UPDATE videos
-		SET latest_job_id = $2,
+		SET upload_state = $3,
 		    updated_at = now()
-		WHERE id = $1
+		WHERE id = $1 AND upload_state = $2 AND ($4::text IS NULL OR tenant_id = $4)
 	`
-	res, err := v.db.ExecContext(ctx, q, videoID, jobID)
+	res, err := v.db.ExecContext(ctx, q, id, string(from), string(to), tenantScope(ctx))
 	if err != nil {
 		return err
 	}
 	aff, _ := res.RowsAffected()
 	if aff == 0 {
-		return ErrNotFound
+		if _, err := v.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
 	}
 	return nil
 }
 
-func (v *VideoStore) MarkProcessing(ctx context.Context, id string) error {
-	return v.setStatus(ctx, id, Processing, nil)
-}
-
-func (v *VideoStore) MarkReady(ctx context.Context, id string) error {
-	// clear error_msg on success
-	empty := (*string)(nil)
-	return v.setStatus(ctx, id, Ready, empty)
-}
-
-func (v *VideoStore) MarkFailed(ctx context.Context, id, msg string) error {
-	return v.setStatus(ctx, id, Failed, &msg)
+// Update applies the set fields of p if the video's updated_at still equals
+// ifUpdatedAt, and returns the updated video. A video that changed since
+// returns ErrConflict.
+func (v *VideoStore) Update(ctx context.Context, id string, p VideoPatch, ifUpdatedAt time.Time) (Video, error) {
+	const q = `
+		UPDATE videos
+		SET title = COALESCE($3, title),
+		    description = COALESCE($4, description),
+		    thumbnail_key = COALESCE($5, thumbnail_key),
+		    updated_at = now()
+		WHERE id = $1 AND updated_at = $2 AND ($6::text IS NULL OR tenant_id = $6)
+	`
+	res, err := v.db.ExecContext(ctx, q, id, ifUpdatedAt, p.Title, p.Description, p.ThumbnailKey, tenantScope(ctx))
+	if err != nil {
+		return Video{}, err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		// tell a missing video apart from a stale precondition
+		if _, err := v.Get(ctx, id); err != nil {
+			return Video{}, err
+		}
+		return Video{}, ErrConflict
+	}
+	return v.Get(ctx, id)
 }
 
-// ---- internal helper ----
-
-func (v *VideoStore) setStatus(ctx context.Context, id string, status Status, errMsg *string) error {
+func setLatestJob(ctx context.Context, db dbtx, videoID, jobID string) error {
 	const q = `
 		UPDATE videos
-		SET status = $2,
-		    error_msg = $3,
+		SET latest_job_id = $2,
 		    updated_at = now()
 		WHERE id = $1
 	`
-	res, err := v.db.ExecContext(ctx, q, id, string(status), errMsg)
+	res, err := db.ExecContext(ctx, q, videoID, jobID)
 	if err != nil {
 		return err
 	}
@@ -211,3 +184,100 @@ func (v *VideoStore) setStatus(ctx context.Context, id string, status Status, er
 	return nil
 }
 
+func scanVideo(row rowScanner) (Video, error) {
+	var out Video
+	var latestJob sql.NullString
+	var errMsg sql.NullString
+	var uploadState string
+	var uploadID sql.NullString
+	var partSize sql.NullInt64
+	var size sql.NullInt64
+	var duration sql.NullFloat64
+	var owner sql.NullString
+	var status string
+
+	err := row.Scan(
+		&out.ID,
+		&out.TenantID,
+		&out.Title,
+		&out.Description,
+		&out.Filename,
+		&out.ContentType,
+		&out.InputKey,
+		&out.ThumbnailKey,
+		&latestJob,
+		&status,
+		&errMsg,
+		&uploadState,
+		&uploadID,
+		&partSize,
+		&size,
+		&duration,
+		&owner,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		return Video{}, err
+	}
+
+	out.Status = Status(status)
+
+	if latestJob.Valid {
+		out.LatestJobID = &latestJob.String
+	}
+	if errMsg.Valid {
+		out.ErrorMsg = &errMsg.String
+	}
+	out.UploadState = UploadState(uploadState)
+	if uploadID.Valid {
+		out.UploadID = &uploadID.String
+	}
+	if partSize.Valid {
+		out.UploadPartSize = &partSize.Int64
+	}
+	if size.Valid {
+		out.SizeBytes = &size.Int64
+	}
+	if duration.Valid {
+		out.DurationSeconds = &duration.Float64
+	}
+	if owner.Valid {
+		out.Owner = &owner.String
+	}
+
+	return out, nil
+}
+
+func insertVideo(ctx context.Context, db dbtx, video Video) error {
+	const q = `
+		INSERT INTO videos
+			(id, title, description, filename, content_type, input_key, thumbnail_key, latest_job_id, status, error_msg,
+			 upload_state, upload_id, upload_part_size, owner, tenant_id)
+		VALUES
+			($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+	`
+	if video.UploadState == "" {
+		video.UploadState = UploadPending
+	}
+	_, err := db.ExecContext(
+		ctx,
+		q,
+		video.ID,
+		video.Title,
+		video.Description,
+		video.Filename,
+		video.ContentType,
+		video.InputKey,
+		video.ThumbnailKey,
+		video.LatestJobID, // can be nil
+		string(video.Status),
+		video.ErrorMsg, // can be nil
+		string(video.UploadState),
+		video.UploadID,
+		video.UploadPartSize,
+		video.Owner, // nil without auth
+		tenantOrDefault(video.TenantID),
+	)
+	return err
+}
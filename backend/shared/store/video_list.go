@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// VideoSort is a List order. The zero value sorts newest first.
+type VideoSort string
+
+const (
+	SortNewest       VideoSort = "-created_at"
+	SortOldest       VideoSort = "created_at"
+	SortTitle        VideoSort = "title"
+	SortTitleDesc    VideoSort = "-title"
+	SortDuration     VideoSort = "duration"
+	SortDurationDesc VideoSort = "-duration"
+)
+
+func (s VideoSort) Valid() bool {
+	switch s {
+	case "", SortNewest, SortOldest, SortTitle, SortTitleDesc, SortDuration, SortDurationDesc:
+		return true
+	}
+	return false
+}
+
+// VideoFilter selects and orders videos. Unset fields don't filter.
+type VideoFilter struct {
+	Owner       *string // only videos created by this subject
+	Statuses    []Status
+	Query       string // full-text search on title and description
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time // exclusive
+	MinDuration *float64   // seconds
+	MaxDuration *float64
+
+	Sort  VideoSort
+	Limit int
+	After *VideoCursor // continue after this row
+}
+
+// VideoCursor is the sort key of the last row of a page.
+type VideoCursor struct {
+	Sort  VideoSort `json:"s"`
+	Value string    `json:"v"`
+	ID    string    `json:"id"`
+}
+
+// sortKey returns the SQL expression, the cast for cursor values and the
+// direction of s. Each has a matching (expr, id) index.
+func (s VideoSort) sortKey() (expr, cast string, desc bool) {
+	switch s {
+	case SortOldest:
+		return "created_at", "timestamptz", false
+	case SortTitle:
+		return "lower(title)", "text", false
+	case SortTitleDesc:
+		return "lower(title)", "text", true
+	case SortDuration:
+		return "COALESCE(duration_seconds, 0)", "float8", false
+	case SortDurationDesc:
+		return "COALESCE(duration_seconds, 0)", "float8", true
+	default:
+		return "created_at", "timestamptz", true
+	}
+}
+
+func (s VideoSort) cursorValue(v Video) string {
+	switch s {
+	case SortTitle, SortTitleDesc:
+		return strings.ToLower(v.Title)
+	case SortDuration, SortDurationDesc:
+		d := 0.0
+		if v.DurationSeconds != nil {
+			d = *v.DurationSeconds
+		}
+		return strconv.FormatFloat(d, 'g', -1, 64)
+	default:
+		return v.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// List returns one page of videos and the cursor of the next page, or nil
+// on the last one. Pages are keyset-paginated, so deep pages cost the same
+// as the first.
+func (v *VideoStore) List(ctx context.Context, f VideoFilter) ([]Video, *VideoCursor, error) {
+	if f.Sort == "" {
+		f.Sort = SortNewest
+	}
+	if f.After != nil && f.After.Sort != f.Sort {
+		return nil, nil, fmt.Errorf("cursor was made for sort %q", f.After.Sort)
+	}
+
+	where, args := f.where(ctx)
+	expr, cast, desc := f.Sort.sortKey()
+
+	dir, cmp := "ASC", ">"
+	if desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if f.After != nil {
+		args = append(args, f.After.Value, f.After.ID)
+		where = append(where, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)", expr, cmp, len(args)-1, cast, len(args)))
+	}
+
+	q := `SELECT ` + videoColumns + ` FROM videos`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	// one extra row tells whether there is a next page
+	args = append(args, f.Limit+1)
+	q += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT $%d`, expr, dir, dir, len(args))
+
+	rows, err := v.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Video, 0, f.Limit)
+	for rows.Next() {
+		item, err := scanVideo(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(out) <= f.Limit {
+		return out, nil, nil
+	}
+	out = out[:f.Limit]
+	last := out[len(out)-1]
+	return out, &VideoCursor{Sort: f.Sort, Value: f.Sort.cursorValue(last), ID: last.ID}, nil
+}
+
+// Count returns how many videos match f, ignoring paging. It is a full
+// scan of the matches, so callers only ask for it on demand.
+func (v *VideoStore) Count(ctx context.Context, f VideoFilter) (int, error) {
+	where, args := f.where(ctx)
+
+	q := `SELECT COUNT(*) FROM videos`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	var n int
+	err := v.db.QueryRowContext(ctx, q, args...).Scan(&n)
+	return n, err
+}
+
+func (f VideoFilter) where(ctx context.Context) ([]string, []any) {
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if t := tenantScope(ctx); t != nil {
+		where = append(where, "tenant_id = "+arg(*t))
+	}
+	if f.Owner != nil {
+		where = append(where, "owner = "+arg(*f.Owner))
+	}
+	if len(f.Statuses) > 0 {
+		ss := make([]string, 0, len(f.Statuses))
+		for _, s := range f.Statuses {
+			ss = append(ss, string(s))
+		}
+		where = append(where, "status = ANY("+arg(pq.Array(ss))+")")
+	}
+	if q := strings.TrimSpace(f.Query); q != "" {
+		where = append(where, "search @@ websearch_to_tsquery('english', "+arg(q)+")")
+	}
+	if f.CreatedFrom != nil {
+		where = append(where, "created_at >= "+arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		where = append(where, "created_at < "+arg(*f.CreatedTo))
+	}
+	if f.MinDuration != nil {
+		where = append(where, "duration_seconds >= "+arg(*f.MinDuration))
+	}
+	if f.MaxDuration != nil {
+		where = append(where, "duration_seconds <= "+arg(*f.MaxDuration))
+	}
+	return where, args
+}
@@ -0,0 +1,321 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (s *WebhookStore) CreateSubscription(ctx context.Context, sub WebhookSubscription) error {
+	const q = `
+		INSERT INTO webhook_subscriptions
+			(id, url, secret, description, events, active)
+		VALUES
+			($1,$2,$3,$4,$5,$6)
+	`
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	_, err := s.db.ExecContext(ctx, q,
+		sub.ID,
+		sub.URL,
+		sub.Secret,
+		sub.Description,
+		pq.Array(sub.Events),
+		sub.Active,
+	)
+	return mapPQError(err)
+}
+
+func (s *WebhookStore) GetSubscription(ctx context.Context, id string) (WebhookSubscription, error) {
+	const q = `
+		SELECT id, url, secret, description, events, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE id=$1
+	`
+	sub, err := scanSubscription(s.db.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return WebhookSubscription{}, ErrNotFound
+		}
+		return WebhookSubscription{}, err
+	}
+	return sub, nil
+}
+
+func (s *WebhookStore) ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	const q = `
+		SELECT id, url, secret, description, events, active, created_at, updated_at
+		FROM webhook_subscriptions
+		ORDER BY created_at DESC
+	`
+	rows, err := s.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sub)
+	}
+	return out, rows.Err()
+}
+
+func (s *WebhookStore) UpdateSubscription(ctx context.Context, sub WebhookSubscription) error {
+	const q = `
+		UPDATE webhook_subscriptions
+		SET url=$2,
+		    description=$3,
+		    events=$4,
+		    active=$5,
+		    updated_at=now()
+		WHERE id=$1
+	`
+	if sub.Events == nil {
+		sub.Events = []string{}
+	}
+	res, err := s.db.ExecContext(ctx, q, sub.ID, sub.URL, sub.Description, pq.Array(sub.Events), sub.Active)
+	if err != nil {
+		return err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *WebhookStore) DeleteSubscription(ctx context.Context, id string) error {
+	const q = `DELETE FROM webhook_subscriptions WHERE id=$1`
+	res, err := s.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	aff, _ := res.RowsAffected()
+	if aff == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *WebhookStore) Emit(ctx context.Context, eventID, eventType string, payload []byte) (int, error) {
+	const q = `
+		INSERT INTO webhook_deliveries
+			(id, subscription_id, event_id, event_type, payload)
+		SELECT gen_random_uuid()::text, id, $1, $2, $3::jsonb
+		FROM webhook_subscriptions
+		WHERE active AND (cardinality(events) = 0 OR $2 = ANY(events))
+	`
+	res, err := s.db.ExecContext(ctx, q, eventID, eventType, string(payload))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (s *WebhookStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	// pushing next_attempt_at forward is the lease: another dispatcher
+	// won't see the row again until it expires
+	const q = `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + make_interval(secs => $2),
+		    updated_at = now()
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+		  AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload,
+		          d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error,
+		          d.created_at, d.updated_at, s.url, s.secret
+	`
+	rows, err := s.db.QueryContext(ctx, q, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows, true)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *WebhookStore) RecordAttempt(ctx context.Context, a WebhookAttempt, status DeliveryStatus, next time.Time) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		const qAttempt = `
+			INSERT INTO webhook_attempts
+				(delivery_id, attempt, status_code, error, duration_ms)
+			VALUES
+				($1,$2,$3,$4,$5)
+		`
+		if _, err := tx.ExecContext(ctx, qAttempt,
+			a.DeliveryID,
+			a.Attempt,
+			a.StatusCode,
+			a.Error,
+			a.Duration.Milliseconds(),
+		); err != nil {
+			return err
+		}
+
+		const qDelivery = `
+			UPDATE webhook_deliveries
+			SET status=$2,
+			    attempts=$3,
+			    next_attempt_at=$4,
+			    last_status_code=$5,
+			    last_error=$6,
+			    updated_at=now()
+			WHERE id=$1
+		`
+		_, err := tx.ExecContext(ctx, qDelivery,
+			a.DeliveryID,
+			string(status),
+			a.Attempt,
+			next,
+			a.StatusCode,
+			a.Error,
+		)
+		return err
+	})
+}
+
+func (s *WebhookStore) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]WebhookDelivery, error) {
+	const q = `
+		SELECT id, subscription_id, event_id, event_type, payload,
+		       status, attempts, next_attempt_at, last_status_code, last_error,
+		       created_at, updated_at
+		FROM webhook_deliveries
+		WHERE subscription_id=$1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, q, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *WebhookStore) ListAttempts(ctx context.Context, deliveryID string) ([]WebhookAttempt, error) {
+	const q = `
+		SELECT delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_attempts
+		WHERE delivery_id=$1
+		ORDER BY attempt
+	`
+	rows, err := s.db.QueryContext(ctx, q, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []WebhookAttempt{}
+	for rows.Next() {
+		var a WebhookAttempt
+		var code sql.NullInt64
+		var errMsg sql.NullString
+		var ms int64
+		if err := rows.Scan(&a.DeliveryID, &a.Attempt, &code, &errMsg, &ms, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		if code.Valid {
+			c := int(code.Int64)
+			a.StatusCode = &c
+		}
+		if errMsg.Valid {
+			a.Error = &errMsg.String
+		}
+		a.Duration = time.Duration(ms) * time.Millisecond
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// ---- internal helpers ----
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (WebhookSubscription, error) {
+	var out WebhookSubscription
+	err := row.Scan(
+		&out.ID,
+		&out.URL,
+		&out.Secret,
+		&out.Description,
+		pq.Array(&out.Events),
+		&out.Active,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	)
+	return out, err
+}
+
+func scanDelivery(row rowScanner, withTarget bool) (WebhookDelivery, error) {
+	var out WebhookDelivery
+	var status string
+	var code sql.NullInt64
+	var errMsg sql.NullString
+
+	dest := []any{
+		&out.ID,
+		&out.SubscriptionID,
+		&out.EventID,
+		&out.EventType,
+		&out.Payload,
+		&status,
+		&out.Attempts,
+		&out.NextAttemptAt,
+		&code,
+		&errMsg,
+		&out.CreatedAt,
+		&out.UpdatedAt,
+	}
+	if withTarget {
+		dest = append(dest, &out.URL, &out.Secret)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	out.Status = DeliveryStatus(status)
+	if code.Valid {
+		c := int(code.Int64)
+		out.LastStatusCode = &c
+	}
+	if errMsg.Valid {
+		out.LastError = &errMsg.String
+	}
+	return out, nil
+}
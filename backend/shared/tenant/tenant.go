@@ -0,0 +1,130 @@
+// Package tenant resolves where each tenant's objects live and carries the
+// tenant of a request in its context.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Default is the tenant of callers without one, and of everything created
+// before tenants existed. It uses S3_BUCKET and S3_BASE_PATH.
+const Default = "default"
+
+var ErrUnknown = errors.New("unknown tenant")
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Tenant is where a tenant's inputs, thumbnails and outputs are stored:
+// <BasePath>inputs/, <BasePath>thumbnails/ and <BasePath>outputs/ in Bucket.
+type Tenant struct {
+	ID       string
+	Bucket   string
+	BasePath string
+}
+
+// Registry is the set of configured tenants.
+type Registry struct {
+	tenants map[string]Tenant
+}
+
+// Parse reads TENANTS, a comma-separated list of id=bucket[:prefix]. An
+// empty bucket means def's bucket, and a missing prefix means
+// "<def prefix>tenants/<id>/", so tenants sharing a bucket never share a
+// prefix. def is the default tenant.
+//
+//	TENANTS=acme=acme-videos:videos/,globex=:globex/
+func Parse(spec string, def Tenant) (*Registry, error) {
+	def.ID = Default
+	r := &Registry{tenants: map[string]Tenant{Default: def}}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, loc, ok := strings.Cut(entry, "=")
+		if !ok || !validID.MatchString(id) {
+			return nil, fmt.Errorf("tenant %q: expected id=bucket[:prefix] with a lowercase id", entry)
+		}
+		if _, dup := r.tenants[id]; dup {
+			return nil, fmt.Errorf("tenant %q configured twice", id)
+		}
+
+		bucket, prefix, hasPrefix := strings.Cut(loc, ":")
+		t := Tenant{ID: id, Bucket: bucket, BasePath: prefix}
+		if t.Bucket == "" {
+			t.Bucket = def.Bucket
+		}
+		if !hasPrefix {
+			t.BasePath = def.BasePath + "tenants/" + id + "/"
+		}
+		if t.BasePath != "" && !strings.HasSuffix(t.BasePath, "/") {
+			t.BasePath += "/"
+		}
+		r.tenants[id] = t
+	}
+
+	// a prefix inside another tenant's prefix would let purges and S3
+	// events of one reach the other
+	for _, a := range r.tenants {
+		for _, b := range r.tenants {
+			if a.ID != b.ID && a.Bucket == b.Bucket && isInside(a, b) {
+				return nil, fmt.Errorf("tenants %q and %q overlap in bucket %s", a.ID, b.ID, a.Bucket)
+			}
+		}
+	}
+	return r, nil
+}
+
+// isInside reports whether a's input, thumbnail or output prefix lies in
+// one of b's.
+func isInside(a, b Tenant) bool {
+	for _, dir := range []string{"inputs/", "thumbnails/", "outputs/"} {
+		for _, other := range []string{"inputs/", "thumbnails/", "outputs/"} {
+			if strings.HasPrefix(a.BasePath+dir, b.BasePath+other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Get returns a configured tenant; the empty id is the default tenant.
+func (r *Registry) Get(id string) (Tenant, error) {
+	if id == "" {
+		id = Default
+	}
+	t, ok := r.tenants[id]
+	if !ok {
+		return Tenant{}, fmt.Errorf("%w %q", ErrUnknown, id)
+	}
+	return t, nil
+}
+
+// ForInput finds the tenant whose inputs prefix holds key in bucket.
+func (r *Registry) ForInput(bucket, key string) (Tenant, bool) {
+	for _, t := range r.tenants {
+		if t.Bucket == bucket && strings.HasPrefix(key, t.BasePath+"inputs/") {
+			return t, true
+		}
+	}
+	return Tenant{}, false
+}
+
+type ctxKey struct{}
+
+// NewContext scopes ctx to tenant id. Stores filter by it.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the tenant ctx is scoped to. Background work that
+// spans tenants runs unscoped.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok
+}
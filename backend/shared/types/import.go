@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// ImportVideoReq creates a video from a source URL. The ingest worker
+// fetches it into the inputs prefix; with enqueue the job starts once the
+// input is in place.
+type ImportVideoReq struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	SourceURL   string `json:"sourceUrl"`
+	Filename    string `json:"filename,omitempty"` // defaults to the last path segment of sourceUrl
+	SHA256      string `json:"sha256,omitempty"`   // expected hex digest of the source
+
+	Enqueue  *bool      `json:"enqueue,omitempty"` // default true
+	Pipeline string     `json:"pipeline,omitempty"`
+	Options  JobOptions `json:"options"`
+}
+
+type ImportResp struct {
+	VideoID       string     `json:"videoId"`
+	SourceURL     string     `json:"sourceUrl"`
+	Status        string     `json:"status"` // pending, running, completed, failed
+	BytesReceived int64      `json:"bytesReceived"`
+	BytesTotal    *int64     `json:"bytesTotal,omitempty"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+type ImportVideoResp struct {
+	Video  VideoResp  `json:"video"`
+	Import ImportResp `json:"import"`
+}
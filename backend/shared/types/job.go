@@ -0,0 +1,53 @@
+package types
+
+import "time"
+
+type JobResp struct {
+	ID                  string     `json:"id"`
+	VideoID             string     `json:"videoId"`
+	InputKey            string     `json:"inputKey"`
+	Pipeline            string     `json:"pipeline"`
+	Options             JobOptions `json:"options"`
+	Status              string     `json:"status"`
+	Progress            int        `json:"progress"`
+	ErrorMsg            *string    `json:"errorMsg,omitempty"`
+	PlaybackReady       bool       `json:"playbackReady"`
+	AvailableRenditions []string   `json:"availableRenditions"`
+	MasterKey           *string    `json:"masterKey,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+type CreateVideoJobResp struct {
+	VideoID          string   `json:"videoId"`
+	JobID            string   `json:"jobId"`
+	Status           string   `json:"status"`
+	SupersededJobIDs []string `json:"supersededJobIds"` // with onConflict=supersede
+}
+
+type JobListResp struct {
+	Items  []JobResp `json:"items"`
+	Total  int       `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
+
+type JobEventResp struct {
+	ID        int64          `json:"id"`
+	Type      string         `json:"type"`
+	Status    string         `json:"status"`
+	Progress  int            `json:"progress"`
+	Attempt   int            `json:"attempt,omitempty"`
+	Worker    string         `json:"worker,omitempty"`
+	Error     *string        `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// JobDetailResp is a job with its full event timeline, oldest first.
+type JobDetailResp struct {
+	JobResp
+	Attempts   int             `json:"attempts"` // highest worker attempt seen
+	Renditions []RenditionResp `json:"renditions"`
+	Events     []JobEventResp  `json:"events"`
+}
@@ -0,0 +1,90 @@
+package types
+
+import "fmt"
+
+// PipelineHLS is the only pipeline the worker runs.
+const PipelineHLS = "hls"
+
+// ValidatePipeline rejects pipelines the worker can't run; empty means
+// PipelineHLS.
+func ValidatePipeline(p string) error {
+	switch p {
+	case "", PipelineHLS:
+		return nil
+	}
+	return fmt.Errorf("unsupported pipeline %q", p)
+}
+
+const (
+	LadderStandard = "standard" // 480p, 720p, 1080p
+	LadderMobile   = "mobile"   // 360p, 480p, 720p
+	LadderHD       = "hd"       // 720p, 1080p
+
+	VideoCodecH264 = "h264"
+	VideoCodecH265 = "h265"
+
+	AudioCodecAAC  = "aac"
+	AudioCodecAC3  = "ac3"
+	AudioCodecNone = "none"
+
+	PriorityLow    = 1
+	PriorityNormal = 2
+	PriorityHigh   = 3
+
+	DefaultSegmentSeconds = 4
+	MaxSegmentSeconds     = 30
+)
+
+// JobOptions tunes a single transcode. The zero value means "defaults".
+type JobOptions struct {
+	LadderProfile  string `json:"ladderProfile,omitempty"`
+	VideoCodec     string `json:"videoCodec,omitempty"`
+	AudioCodec     string `json:"audioCodec,omitempty"`
+	Priority       int    `json:"priority,omitempty"`
+	SegmentSeconds int    `json:"segmentSeconds,omitempty"`
+}
+
+// WithDefaults fills every empty field.
+func (o JobOptions) WithDefaults() JobOptions {
+	if o.LadderProfile == "" {
+		o.LadderProfile = LadderStandard
+	}
+	if o.VideoCodec == "" {
+		o.VideoCodec = VideoCodecH264
+	}
+	if o.AudioCodec == "" {
+		o.AudioCodec = AudioCodecAAC
+	}
+	if o.Priority == 0 {
+		o.Priority = PriorityNormal
+	}
+	if o.SegmentSeconds == 0 {
+		o.SegmentSeconds = DefaultSegmentSeconds
+	}
+	return o
+}
+
+func (o JobOptions) Validate() error {
+	switch o.LadderProfile {
+	case "", LadderStandard, LadderMobile, LadderHD:
+	default:
+		return fmt.Errorf("unknown ladderProfile %q", o.LadderProfile)
+	}
+	switch o.VideoCodec {
+	case "", VideoCodecH264, VideoCodecH265:
+	default:
+		return fmt.Errorf("unknown videoCodec %q", o.VideoCodec)
+	}
+	switch o.AudioCodec {
+	case "", AudioCodecAAC, AudioCodecAC3, AudioCodecNone:
+	default:
+		return fmt.Errorf("unknown audioCodec %q", o.AudioCodec)
+	}
+	if o.Priority < 0 || o.Priority > PriorityHigh {
+		return fmt.Errorf("priority must be between %d and %d", PriorityLow, PriorityHigh)
+	}
+	if o.SegmentSeconds < 0 || o.SegmentSeconds > MaxSegmentSeconds {
+		return fmt.Errorf("segmentSeconds must be between 1 and %d", MaxSegmentSeconds)
+	}
+	return nil
+}
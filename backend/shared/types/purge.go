@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// PurgeResp reports the S3 cleanup of a deleted video.
+type PurgeResp struct {
+	VideoID        string     `json:"videoId"`
+	Status         string     `json:"status"` // pending, running, completed, failed
+	Prefixes       []string   `json:"prefixes"`
+	ObjectsDeleted int64      `json:"objectsDeleted"`
+	Attempts       int        `json:"attempts"`
+	LastError      *string    `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+}
@@ -1,6 +1,5 @@
 // package types
 
-
 // type PresignVideoUploadReq struct {
 // 	Title         string `json:"title"`
 // 	Description   string `json:"description"`
@@ -10,7 +9,6 @@
 // 	ThumbType     string `json:"thumbType"`  // image/png, image/jpeg
 // }
 
-
 // type PresignVideoUploadResp struct {
 // 	VideoID     string `json:"videoId"`
 // 	VideoKey    string `json:"videoKey"`
@@ -31,7 +29,6 @@
 // 	MasterURL          string   `json:"masterUrl,omitempty"`
 // }
 
-
 package types
 
 type PresignVideoUploadReq struct {
@@ -53,7 +50,11 @@ type PresignVideoUploadResp struct {
 }
 
 type CreateVideoJobReq struct {
-	Pipeline string `json:"pipeline"` // "hls"
+	Pipeline string     `json:"pipeline"` // "hls"
+	Options  JobOptions `json:"options"`
+	// OnConflict is what happens to a queued or processing job of the
+	// video: "reject" (default), "supersede" or "queue".
+	OnConflict string `json:"onConflict,omitempty"`
 }
 
 type PlaybackResp struct {
@@ -61,16 +62,36 @@ type PlaybackResp struct {
 	JobID               *string  `json:"jobId,omitempty"`
 	Status              string   `json:"status"`
 	Progress            int      `json:"progress"`
-	PlaybackReady        bool     `json:"playbackReady"`
+	PlaybackReady       bool     `json:"playbackReady"`
 	AvailableRenditions []string `json:"availableRenditions,omitempty"`
 	MasterKey           *string  `json:"masterKey,omitempty"`
 	MasterURL           string   `json:"masterUrl,omitempty"`
+
+	Renditions []RenditionResp `json:"renditions,omitempty"`
 }
 
+// RenditionResp describes one HLS variant of a job. Bitrates are in kbps;
+// measuredBitrate is only known once the rendition is encoded.
+type RenditionResp struct {
+	Name            string  `json:"name"`
+	VideoCodec      string  `json:"videoCodec"`
+	AudioCodec      string  `json:"audioCodec"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	TargetBitrate   int     `json:"targetBitrate"`
+	MeasuredBitrate *int    `json:"measuredBitrate,omitempty"`
+	SegmentCount    int     `json:"segmentCount"`
+	TotalBytes      int64   `json:"totalBytes"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	PlaylistKey     string  `json:"playlistKey"`
+	Status          string  `json:"status"`
+}
 
 type TranscodeJobMessage struct {
-	JobID    string `json:"jobId"`
-	VideoID  string `json:"videoId"`
-	InputKey string `json:"inputKey"`
-	Pipeline string `json:"pipeline"` // "hls"
-}
\ No newline at end of file
+	JobID    string     `json:"jobId"`
+	TenantID string     `json:"tenantId,omitempty"` // picks the bucket and prefix; empty is the default tenant
+	VideoID  string     `json:"videoId"`
+	InputKey string     `json:"inputKey"`
+	Pipeline string     `json:"pipeline"` // "hls"
+	Options  JobOptions `json:"options"`
+}
@@ -0,0 +1,63 @@
+package types
+
+// StartMultipartUploadReq starts a video whose input is uploaded in parts.
+// SizeBytes is optional; when set the part size grows so the upload fits
+// in S3's 10,000 parts.
+type StartMultipartUploadReq struct {
+	PresignVideoUploadReq
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+}
+
+type StartMultipartUploadResp struct {
+	VideoID   string `json:"videoId"`
+	VideoKey  string `json:"videoKey"`
+	UploadID  string `json:"uploadId"`
+	PartSize  int64  `json:"partSize"`
+	PartCount int    `json:"partCount,omitempty"` // only when sizeBytes was sent
+
+	// the thumbnail is small enough for a single PUT
+	ThumbKey    string `json:"thumbKey"`
+	ThumbPutURL string `json:"thumbPutUrl"`
+}
+
+type PresignPartsReq struct {
+	Parts []int32 `json:"parts"` // part numbers, 1-10000
+}
+
+type PartURLResp struct {
+	PartNumber int32  `json:"partNumber"`
+	URL        string `json:"url"`
+}
+
+type PresignPartsResp struct {
+	Parts []PartURLResp `json:"parts"`
+}
+
+type UploadedPartResp struct {
+	PartNumber int32  `json:"partNumber"`
+	ETag       string `json:"etag"`
+	SizeBytes  int64  `json:"sizeBytes"`
+}
+
+// MultipartUploadResp is the state of a multipart upload; a client resumes
+// by uploading the parts that are not listed.
+type MultipartUploadResp struct {
+	VideoID  string             `json:"videoId"`
+	UploadID string             `json:"uploadId"`
+	State    string             `json:"state"`
+	PartSize int64              `json:"partSize"`
+	Parts    []UploadedPartResp `json:"parts"`
+}
+
+type CompletedPartReq struct {
+	PartNumber int32  `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// CompleteMultipartUploadReq completes the upload from Parts, or from the
+// parts S3 has when it is empty, then completes the video like
+// CompleteUploadReq.
+type CompleteMultipartUploadReq struct {
+	CompleteUploadReq
+	Parts []CompletedPartReq `json:"parts,omitempty"`
+}
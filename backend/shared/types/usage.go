@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// UsageResp is GET /v1/usage: the caller's tenant, and the caller if it
+// has limits of its own, against their quotas.
+type UsageResp struct {
+	TenantID    string      `json:"tenantId"`
+	Subject     string      `json:"subject,omitempty"`
+	PeriodStart time.Time   `json:"periodStart"` // encoded minutes count from here
+	Tenant      QuotaUsage  `json:"tenant"`
+	Caller      *QuotaUsage `json:"caller,omitempty"`
+}
+
+type QuotaUsage struct {
+	UploadsLastHour         UsageItem `json:"uploadsLastHour"`
+	ConcurrentJobs          UsageItem `json:"concurrentJobs"`
+	StoredBytes             UsageItem `json:"storedBytes"`
+	EncodedMinutesThisMonth UsageItem `json:"encodedMinutesThisMonth"`
+}
+
+// UsageItem is one measure; Limit is nil when it is unlimited.
+type UsageItem struct {
+	Used  int64  `json:"used"`
+	Limit *int64 `json:"limit"`
+}
@@ -0,0 +1,67 @@
+package types
+
+import "time"
+
+type VideoResp struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"contentType"`
+	InputKey     string    `json:"inputKey"`
+	ThumbnailKey string    `json:"thumbnailKey"`
+	ThumbnailURL string    `json:"thumbnailUrl"`
+	LatestJobID  *string   `json:"latestJobId"`
+	Status       string    `json:"status"`
+	ErrorMsg     *string   `json:"errorMsg"`
+	UploadState  string    `json:"uploadState"`
+	SizeBytes    *int64    `json:"sizeBytes"`
+	Duration     *float64  `json:"durationSeconds"`
+	Owner        *string   `json:"owner"`
+	TenantID     string    `json:"tenantId"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// VideoListResp is one page of GET /v1/videos. Pass NextCursor as cursor
+// to get the next page; it is empty on the last one.
+type VideoListResp struct {
+	Items      []VideoResp `json:"items"`
+	Limit      int         `json:"limit"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	HasMore    bool        `json:"hasMore"`
+	Total      *int        `json:"total,omitempty"` // only with includeTotal=true
+}
+
+// UpdateVideoReq changes only the fields that are set. Setting
+// thumbFilename starts a new thumbnail upload: the response carries a
+// presigned PUT URL for it.
+type UpdateVideoReq struct {
+	Title         *string `json:"title,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	ThumbFilename *string `json:"thumbFilename,omitempty"`
+	ThumbType     string  `json:"thumbType,omitempty"` // image/png, image/jpeg
+
+	// UpdatedAt is the precondition when no If-Match header is sent.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type UpdateVideoResp struct {
+	Video       VideoResp `json:"video"`
+	ThumbKey    string    `json:"thumbKey,omitempty"`
+	ThumbPutURL string    `json:"thumbPutUrl,omitempty"`
+}
+
+// CompleteUploadReq is the optional body of POST /v1/videos/{id}/complete.
+// Enqueue defaults to UPLOAD_AUTO_ENQUEUE; Pipeline and Options are used
+// for the job it creates.
+type CompleteUploadReq struct {
+	Enqueue  *bool      `json:"enqueue,omitempty"`
+	Pipeline string     `json:"pipeline,omitempty"`
+	Options  JobOptions `json:"options"`
+}
+
+type CompleteUploadResp struct {
+	Video VideoResp `json:"video"`
+	JobID *string   `json:"jobId,omitempty"`
+}
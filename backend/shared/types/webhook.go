@@ -0,0 +1,54 @@
+package types
+
+import "time"
+
+type CreateWebhookReq struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`           // empty = every event
+	Secret      string   `json:"secret,omitempty"` // generated when empty
+}
+
+// UpdateWebhookReq only changes the fields that are set.
+type UpdateWebhookReq struct {
+	URL         *string   `json:"url,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Events      *[]string `json:"events,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+type WebhookResp struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"` // only returned on create
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type DeleteWebhookResp struct {
+	ID string `json:"id"`
+}
+
+type WebhookAttemptResp struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode *int      `json:"statusCode,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryResp struct {
+	ID             string               `json:"id"`
+	EventID        string               `json:"eventId"`
+	EventType      string               `json:"eventType"`
+	Status         string               `json:"status"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  *time.Time           `json:"nextAttemptAt,omitempty"`
+	LastStatusCode *int                 `json:"lastStatusCode,omitempty"`
+	LastError      *string              `json:"lastError,omitempty"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	AttemptLog     []WebhookAttemptResp `json:"attemptLog"`
+}
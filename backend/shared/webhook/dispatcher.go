@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"video-encoding/shared/netguard"
+	"video-encoding/shared/store"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxAttempts = 8
+	baseBackoff        = 10 * time.Second
+	maxBackoff         = time.Hour
+)
+
+// Dispatcher sends pending deliveries. Deliveries are leased in the DB, so
+// several dispatchers (one per API replica) can run side by side.
+type Dispatcher struct {
+	store  store.Storage
+	log    *zap.SugaredLogger
+	client *http.Client
+
+	Interval    time.Duration
+	Batch       int
+	MaxAttempts int
+}
+
+// NewDispatcher refuses deliveries to private addresses unless
+// allowPrivate is set (local dev); otherwise any caller could point a
+// webhook at internal services.
+func NewDispatcher(st store.Storage, log *zap.SugaredLogger, allowPrivate bool) *Dispatcher {
+	// checked on every dial, so redirects can't reach inside either
+	dialer := netguard.Dialer(5*time.Second, allowPrivate)
+	return &Dispatcher{
+		store:       st,
+		log:         log,
+		Interval:    2 * time.Second,
+		Batch:       20,
+		MaxAttempts: defaultMaxAttempts,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.dispatchOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.log.Warnw("webhook dispatch failed", "err", err)
+		}
+
+		if n == d.Batch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.Interval):
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchOnce(ctx context.Context) (int, error) {
+	// lease must outlive the HTTP timeout
+	batch, err := d.store.Webhook.Claim(ctx, d.Batch, 2*d.client.Timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, del := range batch {
+		wg.Add(1)
+		go func(del store.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, del)
+		}(del)
+	}
+	wg.Wait()
+
+	return len(batch), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, del store.WebhookDelivery) {
+	attempt := del.Attempts + 1
+	a := store.WebhookAttempt{DeliveryID: del.ID, Attempt: attempt}
+
+	start := time.Now()
+	code, err := d.post(ctx, del)
+	a.Duration = time.Since(start)
+
+	if code != 0 {
+		a.StatusCode = &code
+	}
+
+	status := store.DeliverySucceeded
+	next := time.Now()
+	if err != nil {
+		msg := err.Error()
+		a.Error = &msg
+
+		// a private destination won't become public by retrying
+		if attempt >= d.MaxAttempts || errors.Is(err, netguard.ErrPrivateAddr) {
+			status = store.DeliveryFailed
+			d.log.Warnw("webhook delivery gave up", "deliveryId", del.ID, "event", del.EventType, "url", del.URL, "attempts", attempt, "err", err)
+		} else {
+			status = store.DeliveryPending
+			next = next.Add(backoff(attempt))
+		}
+	}
+
+	if err := d.store.Webhook.RecordAttempt(ctx, a, status, next); err != nil {
+		d.log.Errorw("webhook attempt not recorded", "deliveryId", del.ID, "err", err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, del store.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "video-encoding-webhooks/1")
+	req.Header.Set(HeaderID, del.ID)
+	req.Header.Set(HeaderEvent, del.EventType)
+
+	ts := time.Now().Unix()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(del.Secret, ts, del.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff grows 10s, 20s, 40s, ... up to an hour.
+func backoff(attempt int) time.Duration {
+	b := baseBackoff
+	for i := 1; i < attempt && b < maxBackoff; i++ {
+		b *= 2
+	}
+	if b > maxBackoff {
+		b = maxBackoff
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"video-encoding/shared/store"
+
+	"github.com/google/uuid"
+)
+
+// Event types a subscription can ask for.
+const (
+	EventVideoUploaded = "video.uploaded"
+	EventJobStarted    = "job.started"
+	EventJobProgress   = "job.progress"
+	EventJobCompleted  = "job.completed"
+	EventJobFailed     = "job.failed"
+)
+
+var EventTypes = []string{
+	EventVideoUploaded,
+	EventJobStarted,
+	EventJobProgress,
+	EventJobCompleted,
+	EventJobFailed,
+}
+
+func KnownEvent(t string) bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body POSTed to subscribers.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+type VideoData struct {
+	VideoID string `json:"videoId"`
+	Title   string `json:"title,omitempty"`
+	Status  string `json:"status"`
+}
+
+type JobData struct {
+	JobID      string   `json:"jobId"`
+	VideoID    string   `json:"videoId"`
+	Status     string   `json:"status"`
+	Progress   int      `json:"progress"`
+	Renditions []string `json:"renditions,omitempty"`
+	MasterKey  string   `json:"masterKey,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Emit records one delivery per subscribed endpoint; the dispatcher sends
+// them. It only touches the DB, so any service can call it.
+func Emit(ctx context.Context, st store.Storage, eventType string, data any) error {
+	ev := Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = st.Webhook.Emit(ctx, ev.ID, ev.Type, b)
+	return err
+}
@@ -0,0 +1,54 @@
+import { createHmac } from "node:crypto";
+
+// Mints short-lived API tokens for the browser, so no long-lived credential
+// ends up in the JS bundle. The secret is server-only: it is the API's
+// JWT_HS256_SECRET and must never get a NEXT_PUBLIC_ prefix.
+//
+// The app has no sign-in yet, so there is no session to take the subject
+// from: every caller would get a token for API_TOKEN_SUBJECT and share one
+// owner. That is only acceptable on a developer's machine, so minting is
+// off unless API_TOKEN_DEV_ANONYMOUS=true, and never in production builds.
+// With sign-in, check the session here and use the user's id as `sub`.
+
+export const dynamic = "force-dynamic";
+
+const TTL_SECONDS = 5 * 60;
+
+const noStore = { "Cache-Control": "no-store" };
+
+function b64url(v: string | Buffer) {
+  return Buffer.from(v).toString("base64url");
+}
+
+function anonymousMintingAllowed() {
+  return process.env.API_TOKEN_DEV_ANONYMOUS === "true" && process.env.NODE_ENV !== "production";
+}
+
+export async function GET() {
+  const secret = process.env.API_JWT_SECRET;
+  if (!secret) {
+    // the API runs without AUTH_ENABLED
+    return new Response(null, { status: 204, headers: noStore });
+  }
+  if (!anonymousMintingAllowed()) {
+    return Response.json(
+      { error: "no session: API tokens are only minted for anonymous callers with API_TOKEN_DEV_ANONYMOUS=true in development" },
+      { status: 403, headers: noStore },
+    );
+  }
+
+  const now = Math.floor(Date.now() / 1000);
+  const claims: Record<string, unknown> = {
+    sub: process.env.API_TOKEN_SUBJECT || "web",
+    iat: now,
+    exp: now + TTL_SECONDS,
+  };
+  if (process.env.API_JWT_ISSUER) claims.iss = process.env.API_JWT_ISSUER;
+  if (process.env.API_JWT_AUDIENCE) claims.aud = process.env.API_JWT_AUDIENCE;
+  if (process.env.API_TOKEN_TENANT) claims.tenant = process.env.API_TOKEN_TENANT;
+
+  const unsigned = `${b64url(JSON.stringify({ alg: "HS256", typ: "JWT" }))}.${b64url(JSON.stringify(claims))}`;
+  const sig = createHmac("sha256", secret).update(unsigned).digest("base64url");
+
+  return Response.json({ token: `${unsigned}.${sig}`, expiresAt: claims.exp }, { headers: noStore });
+}
@@ -10,7 +10,7 @@ import { Progress } from "@/components/ui/progress";
 
 import Link from "next/link";
 import { toast } from "sonner";
-import { presignUpload, putFileToPresignedUrl, startJob } from "@/shared/libs/api";
+import { completeUpload, presignUpload, putFileToPresignedUrl } from "@/shared/libs/api";
 
 type Phase = "idle" | "presigning" | "uploading" | "starting" | "done";
 
@@ -59,7 +59,7 @@ export default function UploadPage() {
       setPct(70);
 
       setPhase("starting");
-      await startJob(presign.videoId, "hls");
+      await completeUpload(presign.videoId, { enqueue: true, pipeline: "hls" });
       setPct(95);
 
       setPhase("done");
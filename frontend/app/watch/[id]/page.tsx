@@ -7,7 +7,7 @@ import useSWR from "swr";
 import { Skeleton } from "@/components/ui/skeleton";
 import Link from "next/link";
 import { Button } from "@/components/ui/button";
-import { getPlayback, getVideo } from "@/shared/libs/api";
+import { getPlayback, getVideo, subscribePlayback } from "@/shared/libs/api";
 import PlaybackPanel from "@/shared/components/PlaybackPanel";
 import VideoPlayer from "@/shared/components/VideoPlayer";
 
@@ -16,9 +16,15 @@ export default function WatchPage() {
   const id = params.id;
 
   const videoQ = useSWR(["video", id], () => getVideo(id));
+  // SSE pushes changes; the slow poll is only a safety net
   const playbackQ = useSWR(["playback", id], () => getPlayback(id), {
-    refreshInterval: (data) => (data?.playbackReady ? 15000 : 1500),
+    refreshInterval: 30000,
   });
+  const { mutate } = playbackQ;
+
+  useEffect(() => {
+    return subscribePlayback(id, (p) => mutate(p, { revalidate: false }));
+  }, [id, mutate]);
 
   const pb = playbackQ.data;
 
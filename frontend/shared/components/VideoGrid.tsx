@@ -9,7 +9,7 @@ import { Skeleton } from "@/components/ui/skeleton";
 import { listVideos } from "../libs/api";
 
 export default function VideoGrid() {
-  const { data, isLoading, error } = useSWR(["videos", 24, 0], () => listVideos(24, 0), {
+  const { data, isLoading, error } = useSWR(["videos", 24], () => listVideos(24), {
     refreshInterval: 10_000, // refresh list periodically
   });
   console.log("VideoGrid data:", data);
@@ -1,18 +1,45 @@
 import axios from "axios";
-import type { PlaybackResp, PresignReq, PresignResp, VideoDetail, VideoListItem } from "./types";
+import type { PlaybackResp, PresignReq, PresignResp, VideoDetail, VideoListResp } from "./types";
 
 const baseURL = process.env.NEXT_PUBLIC_API_BASE_URL;
 
 if (!baseURL) throw new Error("Missing NEXT_PUBLIC_API_BASE_URL");
 
+// Short-lived API token minted by app/api/token; undefined when the API
+// runs without AUTH_ENABLED. Refreshed a minute before it expires.
+let cached: { token?: string; expiresAt: number } | undefined;
+let pending: Promise<string | undefined> | undefined;
+
+async function getToken(): Promise<string | undefined> {
+  if (cached && cached.expiresAt - 60 > Date.now() / 1000) return cached.token;
+  pending ??= fetch("/api/token", { cache: "no-store" })
+    .then(async (res) => {
+      if (!res.ok) throw new Error(`token request failed: ${res.status}`);
+      const next: { token?: string; expiresAt: number } =
+        res.status === 204 ? { expiresAt: Infinity } : await res.json();
+      cached = next;
+      return next.token;
+    })
+    .finally(() => {
+      pending = undefined;
+    });
+  return pending;
+}
+
 export const api = axios.create({
   baseURL,
   timeout: 20000,
 });
 
-export async function listVideos(limit = 24, offset = 0) {
-  const { data } = await api.get("/videos", { params: { limit, offset } });
-  return data.data as { items: VideoListItem[]; total: number; limit: number; offset: number };
+api.interceptors.request.use(async (config) => {
+  const token = await getToken();
+  if (token) config.headers.Authorization = `Bearer ${token}`;
+  return config;
+});
+
+export async function listVideos(limit = 24, cursor?: string) {
+  const { data } = await api.get("/videos", { params: { limit, cursor } });
+  return data.data as VideoListResp;
 }
 
 export async function getVideo(id: string) {
@@ -25,6 +52,12 @@ export async function presignUpload(payload: PresignReq) {
   return data.data as PresignResp;
 }
 
+// Verifies the uploaded objects; with enqueue the first job starts right away.
+export async function completeUpload(videoId: string, body: { enqueue?: boolean; pipeline?: string } = {}) {
+  const { data } = await api.post(`/videos/${videoId}/complete`, body);
+  return data.data as { video: VideoDetail; jobId?: string };
+}
+
 export async function startJob(videoId: string, pipeline: string = "hls") {
   const { data } = await api.post(`/videos/${videoId}/jobs`, { pipeline });
   return data.data as { videoId: string; jobId: string; status: string };
@@ -35,6 +68,34 @@ export async function getPlayback(videoId: string) {
   return data.data as PlaybackResp;
 }
 
+// Live playback updates (SSE). Returns a function that closes the stream.
+// The server closes the stream once the job is completed/failed/cancelled.
+export function subscribePlayback(videoId: string, onUpdate: (p: PlaybackResp) => void) {
+  let es: EventSource | undefined;
+  let closed = false;
+
+  getToken()
+    .then((token) => {
+      if (closed) return;
+      // EventSource can't set headers; the token is short-lived and only
+      // checked when the stream opens
+      const qs = token ? `?access_token=${encodeURIComponent(token)}` : "";
+      const source = new EventSource(`${baseURL}/videos/${videoId}/events${qs}`);
+      source.addEventListener("playback", (ev) => {
+        onUpdate(JSON.parse((ev as MessageEvent).data) as PlaybackResp);
+      });
+      // don't let EventSource reconnect after the server ended a finished stream
+      source.onerror = () => source.close();
+      es = source;
+    })
+    .catch(() => {});
+
+  return () => {
+    closed = true;
+    es?.close();
+  };
+}
+
 // Direct-to-S3 PUT
 export async function putFileToPresignedUrl(url: string, file: File, contentType?: string) {
   await axios.put(url, file, {
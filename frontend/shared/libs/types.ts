@@ -1,4 +1,4 @@
-export type VideoStatus = "uploaded" | "processing" | "ready" | "failed";
+export type VideoStatus = "pending_upload" | "uploaded" | "processing" | "ready" | "failed";
 
 export type VideoListItem = {
   id: string;
@@ -7,9 +7,18 @@ export type VideoListItem = {
   thumbnailUrl?: string;
   status: VideoStatus;
   latestJobId?: string | null;
+  durationSeconds?: number | null;
   createdAt: string;
 };
 
+export type VideoListResp = {
+  items: VideoListItem[];
+  limit: number;
+  nextCursor?: string;
+  hasMore: boolean;
+  total?: number;
+};
+
 export type VideoDetail = {
   id: string;
   title: string;
@@ -45,4 +54,20 @@ export type PlaybackResp = {
   availableRenditions?: string[];
   masterKey?: string;
   masterUrl?: string;
+  renditions?: Rendition[];
+};
+
+export type Rendition = {
+  name: string; // "720p"
+  videoCodec: string;
+  audioCodec: string;
+  width: number;
+  height: number;
+  targetBitrate: number; // kbps
+  measuredBitrate?: number; // kbps, once encoded
+  segmentCount: number;
+  totalBytes: number;
+  durationSeconds: number;
+  playlistKey: string;
+  status: "pending" | "ready" | "failed";
 };